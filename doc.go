@@ -56,18 +56,88 @@ string is used as value.
 
 ``nargs''
 
-Specifies the number of arguments the flag requires. The value can a vaild integer. If omitted, then
-``1'' is used as value.
+Specifies the number of arguments the flag requires. The value can a vaild integer or a range of the
+form ``min..max''. If omitted, then ``1'' is used as value, or the length for array types.
+
+A range means the flag greedily consumes at least min and at most max arguments, it stops at the
+next flag or when max arguments have been consumed. For positional flags min must be at least 1.
 
 A negative integer means unlimited (one or more) number of arguments. Multiple flags positional or
-optional can have unlimited arguments in a flagset. However for positional flags you should specify
-only one flag with unlimited arguments and it should be the last positional flag. This limitation
-will go away in some future release.
+optional can have unlimited arguments in a flagset. Arguments meant for positional flags are
+collected first and then distributed among the positional flags in the order they were defined.
+Each positional flag greedily takes as many arguments as it can while leaving enough for the
+flags after it, so a definition like ``cp SRC... DEST'' works as expected.
 
 The value ``0'' is also a bit special. When specified for a positional flag it results in error
 since positional flag must have at least one argument. For an optional flag specifying ``0'' means
 the flag doesn't require any arguments i.e. it is essentially a switch.
 
+``optional''
+
+Marks a positional flag as optional. The value can be either ``true'' or ``false''. An optional
+positional flag may be omitted on the command line in which case it keeps its default value. It is
+shown in brackets in the usage synopsis. Specifying it for an optional flag results in error.
+
+``layout''
+
+Specifies the layout, in the format understood by time.Parse, with which arguments of a time.Time
+or []time.Time flag are parsed and its default value is shown. If omitted, then time.RFC3339 is
+used. Specifying it for any other type results in error.
+
+``dupkeys''
+
+Specifies what happens when a key is given more than once to a map flag. The value can be either
+``last'', the default, in which case the last value of the key wins or ``error'' in which case
+parsing fails. Specifying it for any other type results in error.
+
+``sep''
+
+Specifies a single character at which each argument of a slice, array or map flag is split into
+elements, for e.g. ``--hosts a,b,c''. A separator preceded by a back-slash is not split at, other
+back-slashes and empty elements are kept as is. Since ``,'' separates the key-value pairs of the tag
+it must be escaped as well, like ``sep=\\,''. Elements of all occurrences of the flag are collected,
+and an array flag takes a single argument unless nargs is given too. The elements of all
+occurrences of an array flag must fill the array together, for e.g. ``--point 1,2 --point 3'' for a
+[3]int, and the array is set once all arguments have been processed. Specifying it for any other
+type results in error.
+
+``defport''
+
+Specifies the port used by a HostPort flag when an argument omits it, for e.g. with
+``defport=443'' the argument ``example.com'' results in ``example.com:443''. The value can be a port
+number or a service name. Specifying it for any other type results in error.
+
+``schemes''
+
+Specifies a ``:'' separated list of schemes accepted by a url.URL flag, for e.g.
+``schemes=http:https''. If omitted, then URLs with any scheme are accepted. Specifying it for any
+other type results in error.
+
+``units''
+
+Allows arguments of integer and float flags, or slices of these, to be given with a unit suffix.
+The value can be either ``bytes'' or ``si''. With ``bytes'' arguments are byte sizes like ``512'',
+``10MiB'' or ``1.5GB'' where IEC suffixes (KiB, MiB, GiB, TiB, PiB, EiB, optionally without the B)
+are powers of 1024 and SI suffixes (KB, MB, GB, TB, PB, EB, optionally without the B) are powers
+of 1000, all matched case-insensitively. With ``si'' arguments are quantities like ``2k'' or ``3M''
+using the suffixes k, M, G, T, P and E. Integer flags require the result to be a whole number which
+fits the type. Values are shown in the unit giving the shortest exact number, preferring SI units
+on a tie, for e.g. ``1.5KiB'', ``1.5GB'' or ``512KB''.
+
+``path''
+
+Treats each argument of a string flag, or a slice of strings, as a file system path which must
+satisfy the given check: ``existing-file'' requires an existing, readable file which is not a
+directory, ``existing-dir'' an existing, readable directory, ``not-exist'' a path which does not
+exist yet and ``parent-exist'' a path whose parent directory exists. For both of the latter the
+parent directory, if it exists, must be writable. ``any'' performs no check. A leading ``~'' is
+expanded to the user's home directory. Specifying it for any other type results in error.
+
+``abs''
+
+Can be either ``true'' or ``false''. If true then the arguments of a path flag are made absolute
+and cleaned, relative paths are resolved against the current working directory. Implies
+``path=any'' if ``path'' is not given.
 
 Some examples:
 
@@ -89,13 +159,122 @@ Some examples:
 
 		// a switch flag with name="--f6"
 		Field6  int  `flagparse:"name=--f6,nargs=0"`
+
+		// an optional flag with name="--f7" accepting 2, 3 or 4 arguments
+		Field7  []int  `flagparse:"name=--f7,nargs=2..4"`
+
+		// a positional flag with name="target" which may be omitted
+		Field8  string  `flagparse:"name=target,optional=true"`
+
+		// an optional flag with name="--since" accepting dates like "2006-01-02"
+		Field9  time.Time  `flagparse:"name=--since,layout=2006-01-02"`
+
+		// an optional flag with name="--label" which fails on duplicate keys
+		Field10  map[string]string  `flagparse:"name=--label,dupkeys=error"`
+
+		// an optional flag with name="--hosts" accepting for e.g. "a,b,c"
+		Field11  []string  `flagparse:"name=--hosts,sep=\\,"`
+
+		// an optional flag with name="--buffer" accepting sizes like "64KiB"
+		Field12  int64  `flagparse:"name=--buffer,units=bytes"`
+
+		// an optional flag with name="--config" accepting only existing files, for e.g. "~/app.conf"
+		Field13  string  `flagparse:"name=--config,path=existing-file,abs=true"`
 	}
 
 
+Parsing Modes
+
+By default optional flags may be intermixed with arguments for positional flags. Setting the
+FlagSet's StopAtFirstPositional field stops processing optional flags at the first argument meant
+for a positional flag, everything from that argument onwards is handed to the positional flags
+untouched. This is useful for wrapper tools which forward arguments to another program, for e.g.:
+
+	type wrapper struct {
+		Verbose bool     `flagparse:"name=-v,nargs=0"`
+		Cmd     string   `flagparse:""`
+		Args    []string `flagparse:"nargs=-1,optional=true"`
+	}
+
+With StopAtFirstPositional set, ``wrapper -v ls -l -a'' results in Cmd="ls" and Args=["-l", "-a"].
+
+Layered tools can use ParseKnown instead of Parse. It consumes the flags known to the FlagSet and
+returns unrecognized flags and extra arguments in their original order so that they can be passed
+on to another FlagSet or program.
+
+Setting the FlagSet's AllowAbbrev field allows long optional flags to be abbreviated to any unique
+prefix, for e.g. ``--verb'' for ``--verbose''. An ambiguous prefix results in error.
+
+Repeated Parsing
+
+Parse processes the FlagSet's CmdArgs field, which defaults to os.Args[1:]. To process some other
+arguments use ParseArgs instead. Both can be called any number of times on the same FlagSet, for
+e.g. for every line read in a REPL. Parsing state is reset on every call but values of flags not
+given on the command line are retained, call Reset before parsing to restore every flag to its
+default value.
+
+A FlagSet created by NewFlagSetFrom can also be used to parse many argument lists concurrently,
+each into its own instance of the struct, using ParseInto. ParseInto never modifies the FlagSet or
+the struct it was created from, so it is safe to share a single FlagSet between goroutines.
+
+
+Schema
+
+A FlagSet's Schema method returns a serialisable description of the FlagSet: its name,
+description, positional flags in order and optional flags with all their names, nargs, type,
+default value, usage and any constraints. Use the WriteJSON method of Schema to encode it as JSON
+for tools like documentation sites and GUIs.
+
+The inverse is also possible: NewFlagSetFromSchema and NewFlagSetFromJSON create a FlagSet from
+such a description, collecting the values of the flags in a map[string]interface{} instead of
+struct fields. This lets for e.g. script based plugins declare their flags without Go code.
+
+
+Errors
+
+Errors returned while defining or parsing flags are of the following types, which can be inspected
+using errors.As: UnknownFlagError, ArgCountError, ValueParseError, MissingRequiredError and
+DefinitionError. Parse errors carry the name of the flag involved and its position in CmdArgs.
+
+By default parsing stops at the first error. Setting the FlagSet's CollectErrors field makes it
+continue after recoverable errors and return an ErrorList describing every problem along with its
+position, so that users can fix all of them in one go.
+
+Built-in Types
+
+Flags can be created out of the following types and their slice counterparts: bool, string,
+float64, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+time.Duration, time.Time, net.IP, net.IPNet, HostPort and url.URL. Durations are parsed by
+time.ParseDuration e.g. ``1m30s'' and IP networks are given in CIDR notation e.g. ``10.0.0.0/8''. Integers
+are accepted in any base understood by strconv.ParseInt e.g. ``0x1f'' and a value which does not
+fit in the target type's size results in an out of range error. Times are parsed as per the
+``layout'' key, see above.
+
 User Defined Types
 
 The package provides support for common built-in types but it is easy to extend this support
 to other types including your own by simply implementing the Value interface. Please see the
 interface's documentation for more details.
+
+Slices and arrays of any supported type, for e.g. []int16 or [3]float64, can be used as well. Each
+argument is parsed into one element and an array flag requires exactly as many arguments as the
+array's length.
+
+Maps with string keys and values of any supported type, for e.g. map[string]string, are supported
+too. Each argument of a map flag is a ``key=value'' pair split at the first ``='', use a backslash
+to escape a ``='' which is part of the key. The value is taken as is and may be empty or contain
+further ``='' characters, for e.g. ``OPTS=-Dx=y''. Pairs of all occurrences of a map flag are
+collected, so ``--label a=1 --label b=2'' results in both keys being set.
+
+Pointers to any supported type, for e.g. *int or *time.Duration, can be used to tell apart a flag
+which was not given from one given with the zero value. Such a pointer is left nil until the flag
+is given, in which case it is pointed to a newly allocated variable, and a nil default is shown as
+``none''.
+
+Types whose pointer implements the standard library's flag.Value interface or
+encoding.TextUnmarshaler are supported as well, which covers types like big.Int and most enums. A
+flag.Value has its Set method called once for each argument, while UnmarshalText is given the first
+argument only. If such a type also implements encoding.TextMarshaler then it is used to show the
+default value.
 */
 package flagparse
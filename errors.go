@@ -0,0 +1,138 @@
+package flagparse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// UnknownFlagError is returned when an optional flag or an argument cannot be matched to any flag
+// in the FlagSet.
+type UnknownFlagError struct {
+	Name string // the unrecognized optional flag or argument
+	Pos  int    // index of Name in the command line arguments
+	// Argument is true if Name is an argument left over after satisfying all positional flags
+	// rather than an optional flag.
+	Argument bool
+	// Ambiguous is true if Name is an abbreviation matching more than one optional flag.
+	Ambiguous bool
+	// Candidates contains the optional flag names Name could match if it is ambiguous, otherwise
+	// the closest optional flag names to suggest to the user.
+	Candidates []string
+}
+
+func (e *UnknownFlagError) Error() string {
+	switch {
+	case e.Ambiguous:
+		return fmt.Sprintf("ambiguous option %s could match %s", e.Name, strings.Join(e.Candidates, ", "))
+	case e.Argument:
+		return fmt.Sprintf("unrecognized argument: %s%s", e.Name, didYouMean(e.Candidates))
+	default:
+		return fmt.Sprintf("unrecognized flag %s%s", e.Name, didYouMean(e.Candidates))
+	}
+}
+
+// ArgCountError is returned when a flag is given fewer or more arguments than its nargs allows.
+type ArgCountError struct {
+	Flag  string // name of the flag
+	Pos   int    // index of the flag, or of its first argument if positional, in the arguments
+	Min   int    // minimum number of arguments the flag accepts
+	Max   int    // maximum number of arguments the flag accepts, negative if unlimited
+	Given int    // number of arguments actually given
+}
+
+func (e *ArgCountError) Error() string {
+	return fmt.Sprintf("flag %s expects %s, given %d", e.Flag, describeNArgs(e.Min, e.Max), e.Given)
+}
+
+// ValueParseError is returned when the Value of a flag fails to parse the given arguments.
+type ValueParseError struct {
+	Flag string   // name of the flag
+	Pos  int      // index of the flag, or of its first argument if positional, in the arguments
+	Args []string // arguments passed to the Value
+	Err  error    // error returned by the Value's Set method
+}
+
+func (e *ValueParseError) Error() string {
+	return fmt.Sprintf("invalid argument(s) for flag %s: %s", e.Flag, e.Err)
+}
+
+func (e *ValueParseError) Unwrap() error { return e.Err }
+
+// MissingRequiredError is returned when no arguments are given for mandatory positional flags.
+type MissingRequiredError struct {
+	Flags []string // names of the positional flags missing arguments
+}
+
+func (e *MissingRequiredError) Error() string {
+	return fmt.Sprintf("arguments are required for these flags: %v", strings.Join(e.Flags, ", "))
+}
+
+// DefinitionError is returned when a flag cannot be defined, for e.g. due to an invalid name or
+// struct tag.
+type DefinitionError struct {
+	Field string // name of the struct field the flag is created from, if any
+	Flag  string // name of the flag, if known
+	Err   error  // the underlying error
+}
+
+func (e *DefinitionError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("Error while creating flag from field '%s': %s", e.Field, e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *DefinitionError) Unwrap() error { return e.Err }
+
+// ErrorList is returned by parsing when the FlagSet's CollectErrors field is set and one or more
+// errors occurred. It lists the errors in the order they were encountered.
+type ErrorList []error
+
+func (el ErrorList) Error() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%d error(s) occurred while parsing:", len(el))
+	for _, err := range el {
+		if pos, ok := errorPos(err); ok {
+			fmt.Fprintf(b, "\n  argument %d: %s", pos+1, err)
+		} else {
+			fmt.Fprintf(b, "\n  %s", err)
+		}
+	}
+	return b.String()
+}
+
+// Is reports whether any of the collected errors matches target, so that errors.Is can inspect each
+// of them.
+func (el ErrorList) Is(target error) bool {
+	for _, err := range el {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the collected errors that matches target, so that errors.As can inspect each
+// of them.
+func (el ErrorList) As(target interface{}) bool {
+	for _, err := range el {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorPos returns the position in the command line arguments that err refers to, if any.
+func errorPos(err error) (int, bool) {
+	switch e := err.(type) {
+	case *UnknownFlagError:
+		return e.Pos, true
+	case *ArgCountError:
+		return e.Pos, true
+	case *ValueParseError:
+		return e.Pos, true
+	}
+	return 0, false
+}
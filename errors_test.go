@@ -0,0 +1,212 @@
+package flagparse
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type errorsConfig struct {
+	Pos1 int      `flagparse:""`
+	Pos2 []int    `flagparse:"nargs=2"`
+	Opt1 []string `flagparse:"name=--opt1,nargs=2"`
+	Opt2 int      `flagparse:"name=--opt2"`
+}
+
+func parseErrorsConfig(t *testing.T, args []string) error {
+	fs, err := NewFlagSetFrom(&errorsConfig{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+	fs.CmdArgs = args
+	return fs.Parse()
+}
+
+func Test_UnknownFlagError(t *testing.T) {
+	data := []struct {
+		args     []string
+		expected UnknownFlagError
+	}{
+		{
+			args:     []string{"1", "--opt3", "x"},
+			expected: UnknownFlagError{Name: "--opt3", Pos: 1, Candidates: []string{"--opt1", "--opt2"}},
+		},
+		{
+			args:     []string{"1", "2", "3", "extra"},
+			expected: UnknownFlagError{Name: "extra", Pos: 3, Argument: true},
+		},
+	}
+	for _, input := range data {
+		var target *UnknownFlagError
+		err := parseErrorsConfig(t, input.args)
+		if !errors.As(err, &target) {
+			t.Fatalf("Testing: FlagSet.Parse(); Expected: error of type %T with %q as args; Got: %#v", target, input.args, err)
+		}
+		if !reflect.DeepEqual(*target, input.expected) {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: %+v with %q as args; Got: %+v", input.expected, input.args, *target)
+		}
+	}
+}
+
+func Test_ArgCountError(t *testing.T) {
+	data := []struct {
+		args     []string
+		expected ArgCountError
+	}{
+		{
+			args:     []string{"1", "2", "3", "--opt1", "x"},
+			expected: ArgCountError{Flag: "--opt1", Pos: 3, Min: 2, Max: 2, Given: 1},
+		},
+		{
+			args:     []string{"--opt2", "5", "1", "2"},
+			expected: ArgCountError{Flag: "pos2", Pos: 3, Min: 2, Max: 2, Given: 1},
+		},
+	}
+	for _, input := range data {
+		var target *ArgCountError
+		err := parseErrorsConfig(t, input.args)
+		if !errors.As(err, &target) {
+			t.Fatalf("Testing: FlagSet.Parse(); Expected: error of type %T with %q as args; Got: %#v", target, input.args, err)
+		}
+		if *target != input.expected {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: %+v with %q as args; Got: %+v", input.expected, input.args, *target)
+		}
+	}
+}
+
+func Test_ValueParseError(t *testing.T) {
+	args := []string{"1", "2", "x3"}
+	var target *ValueParseError
+	err := parseErrorsConfig(t, args)
+	if !errors.As(err, &target) {
+		t.Fatalf("Testing: FlagSet.Parse(); Expected: error of type %T with %q as args; Got: %#v", target, args, err)
+	}
+	if target.Flag != "pos2" || target.Pos != 1 || !reflect.DeepEqual(target.Args, []string{"2", "x3"}) {
+		t.Errorf("Testing: FlagSet.Parse(); Expected: error for flag pos2 at 1 with args [2 x3]; Got: %+v", *target)
+	}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		t.Errorf("Testing: FlagSet.Parse(); Expected: Value's error to be wrapped as is; Got: %#v", numErr)
+	}
+	if errors.Unwrap(err) == nil {
+		t.Errorf("Testing: FlagSet.Parse(); Expected: wrapped error from Value; Got: nil")
+	}
+}
+
+func Test_MissingRequiredError(t *testing.T) {
+	var target *MissingRequiredError
+	err := parseErrorsConfig(t, []string{"--opt2", "5"})
+	if !errors.As(err, &target) {
+		t.Fatalf("Testing: FlagSet.Parse(); Expected: error of type %T; Got: %#v", target, err)
+	}
+	if !reflect.DeepEqual(target.Flags, []string{"pos1", "pos2"}) {
+		t.Errorf("Testing: FlagSet.Parse(); Expected: missing flags [pos1 pos2]; Got: %v", target.Flags)
+	}
+}
+
+func Test_DefinitionError(t *testing.T) {
+	_, err := NewFlagSetFrom(&struct {
+		Field1 int `flagparse:"name=--f1,nargs=x"`
+	}{})
+	var target *DefinitionError
+	if !errors.As(err, &target) {
+		t.Fatalf("Testing: NewFlagSetFrom(); Expected: error of type %T; Got: %#v", target, err)
+	}
+	if target.Field != "Field1" {
+		t.Errorf("Testing: NewFlagSetFrom(); Expected: error for field Field1; Got: %+v", *target)
+	}
+
+	fs := NewFlagSet()
+	err = fs.Add(NewIntFlag(new(int), false, ""), "--opt", "no-prefix")
+	if !errors.As(err, &target) {
+		t.Fatalf("Testing: FlagSet.Add(); Expected: error of type %T; Got: %#v", target, err)
+	}
+	if target.Flag != "no-prefix" {
+		t.Errorf("Testing: FlagSet.Add(); Expected: error for flag no-prefix; Got: %+v", *target)
+	}
+}
+
+func Test_ErrorList(t *testing.T) {
+	fs, err := NewFlagSetFrom(&errorsConfig{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+	fs.CollectErrors = true
+	fs.CmdArgs = []string{"x1", "--opt2", "y", "--dummy", "--opt1", "a", "2", "3", "4"}
+	err = fs.Parse()
+	el, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("Testing: FlagSet.Parse(); Expected: error of type %T; Got: %#v", el, err)
+	}
+	// invalid value for --opt2, unknown flag --dummy, invalid value for pos1
+	if len(el) != 3 {
+		t.Fatalf("Testing: FlagSet.Parse(); Expected: 3 errors; Got: %d: %v", len(el), el)
+	}
+	expectedPos := []int{1, 3, 0}
+	for i, e := range el {
+		if pos, _ := errorPos(e); pos != expectedPos[i] {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: error #%d at position %d; Got: %d", i, expectedPos[i], pos)
+		}
+	}
+	var unknownErr *UnknownFlagError
+	if !errors.As(err, &unknownErr) || unknownErr.Name != "--dummy" {
+		t.Errorf("Testing: errors.As(ErrorList); Expected: %T for --dummy; Got: %v", unknownErr, unknownErr)
+	}
+	var parseErr *ValueParseError
+	if !errors.As(fmt.Errorf("wrapped: %w", err), &parseErr) || parseErr.Flag != "--opt2" {
+		t.Errorf("Testing: errors.As(wrapped ErrorList); Expected: %T for --opt2; Got: %v", parseErr, parseErr)
+	}
+	if !errors.Is(err, el[1]) || errors.Is(err, errors.New("other")) {
+		t.Errorf("Testing: errors.Is(ErrorList); Expected: match only for a collected error")
+	}
+
+	// without errors being collected only the first error is returned
+	fs.CollectErrors = false
+	if err := fs.Parse(); err == nil {
+		t.Errorf("Testing: FlagSet.Parse(); Expected: error; Got: no error")
+	} else if _, ok := err.(ErrorList); ok {
+		t.Errorf("Testing: FlagSet.Parse(); Expected: single error; Got: %T", err)
+	}
+}
+
+func Test_ErrorList_AccumulatingFlags(t *testing.T) {
+	cfg := &struct {
+		P []int          `flagparse:"name=--p,sep=\\,"`
+		M map[string]int `flagparse:"name=--m"`
+	}{}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+	fs.CollectErrors = true
+
+	// an invalid argument is reported once, for the occurrence it was given in
+	data := []struct {
+		args     []string
+		expected ValueParseError
+	}{
+		{[]string{"--p", "1,x", "--p", "2", "--p", "3"}, ValueParseError{Flag: "--p", Pos: 0, Args: []string{"1,x"}}},
+		{[]string{"--m", "a=1", "--m", "b=x", "--m", "c=3"}, ValueParseError{Flag: "--m", Pos: 2, Args: []string{"b=x"}}},
+	}
+	for _, input := range data {
+		err := fs.ParseArgs(input.args)
+		el, ok := err.(ErrorList)
+		if !ok || len(el) != 1 {
+			t.Fatalf("Testing: FlagSet.ParseArgs(%q); Expected: 1 error; Got: %v", input.args, err)
+		}
+		pe, ok := el[0].(*ValueParseError)
+		if !ok || pe.Flag != input.expected.Flag || pe.Pos != input.expected.Pos ||
+			!reflect.DeepEqual(pe.Args, input.expected.Args) {
+			t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: %+v; Got: %#v", input.args, input.expected, el[0])
+		}
+	}
+}
@@ -1,19 +1,104 @@
 package flagparse
 
 import (
+	"encoding"
+	stdflag "flag"
 	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
 )
 
 type Flag struct {
+	names      []string // names the flag was added to a FlagSet with
 	defVal     string
 	nArgs      int
+	nArgsMax   int // upper bound when nargs is a range, 0 otherwise
 	positional bool
+	optional   bool // only for positional flags, the flag may be omitted
+	sep        rune // separator at which each argument is split into elements, 0 if none
 	value      Value
 	usage      string
+	// copy of the variable pointed to by value at the time of creation, used for restoring the
+	// default value
+	defCopy reflect.Value
+	// bind creates a Value for the struct field the flag was created from, in the given struct
+	bind func(reflect.Value) (Value, error)
 }
 
+// Names returns the names the flag was added to a FlagSet with. For positional flags it contains a
+// single name whereas for optional flags the first name is followed by any secondary names.
+func (fl *Flag) Names() []string { return append([]string(nil), fl.names...) }
+
+// Usage returns the usage string of the flag.
+func (fl *Flag) Usage() string { return fl.usage }
+
+// NArgs returns the nargs value of the flag. For flags accepting a range of arguments it returns the
+// minimum, see NArgsRange.
+func (fl *Flag) NArgs() int { return fl.nArgs }
+
+// NArgsRange returns the minimum and maximum number of arguments the flag accepts. A negative
+// maximum means there is no upper limit.
+func (fl *Flag) NArgsRange() (int, int) { return fl.argRange() }
+
+// Positional returns true if the flag is positional.
+func (fl *Flag) Positional() bool { return fl.positional }
+
+// Optional returns true if the flag is a positional flag which may be omitted.
+func (fl *Flag) Optional() bool { return fl.optional }
+
+// Separator returns the separator at which the flag's arguments are split, 0 if none.
+func (fl *Flag) Separator() rune { return fl.sep }
+
+// DefValue returns the default value of the flag as shown in the usage message.
+func (fl *Flag) DefValue() string { return fl.defVal }
+
+// Value returns the Value of the flag.
+func (fl *Flag) Value() Value { return fl.value }
+
 func (fl *Flag) isSwitch() bool {
-	return !fl.positional && fl.nArgs == 0
+	return !fl.positional && fl.nArgs == 0 && fl.nArgsMax == 0
+}
+
+// argRange returns the minimum and maximum number of arguments the flag accepts. A negative
+// maximum means there is no upper limit. The minimum of an optional positional flag is 0, see
+// givenArgRange for the arguments it needs when given.
+func (fl *Flag) argRange() (int, int) {
+	min, max := fl.givenArgRange()
+	if fl.optional {
+		min = 0
+	}
+	return min, max
+}
+
+// givenArgRange is like argRange but returns the range of arguments the flag accepts when it is
+// given on the command line, regardless of whether it may be omitted.
+func (fl *Flag) givenArgRange() (int, int) {
+	min, max := fl.nArgs, fl.nArgs
+	if fl.nArgsMax != 0 {
+		max = fl.nArgsMax
+	} else if fl.nArgs < 0 {
+		min, max = 1, -1
+	}
+	return min, max
+}
+
+// describeNArgs returns a human readable description of the number of arguments the flag accepts.
+func (fl *Flag) describeNArgs() string {
+	return describeNArgs(fl.argRange())
+}
+
+func describeNArgs(min, max int) string {
+	switch {
+	case max < 0:
+		return fmt.Sprintf("at least %d argument(s)", min)
+	case min == max:
+		return fmt.Sprintf("%d argument(s)", min)
+	default:
+		return fmt.Sprintf("between %d and %d arguments", min, max)
+	}
 }
 
 func (fl *Flag) optToSwitch() {
@@ -36,17 +121,126 @@ func (fl *Flag) SetNArgs(n int) error {
 		fl.switchToOpt()
 	}
 	fl.nArgs = n
+	fl.nArgsMax = 0
+	return nil
+}
+
+// SetNArgsRange makes the flag accept between min and max arguments. A negative max means there is
+// no upper limit. While parsing, the flag greedily consumes arguments until it either reaches max
+// or encounters the next flag.
+func (fl *Flag) SetNArgsRange(min, max int) error {
+	if min < 0 {
+		return fmt.Errorf("minimum nargs cannot be negative")
+	}
+	if max >= 0 && max < min {
+		return fmt.Errorf("maximum nargs %d is less than minimum nargs %d", max, min)
+	}
+	if min == max {
+		return fl.SetNArgs(min)
+	}
+	if fl.positional && min == 0 {
+		return fmt.Errorf("minimum nargs cannot be 0 for positional flag")
+	}
+	if fl.isSwitch() {
+		fl.switchToOpt()
+	}
+	fl.nArgs = min
+	fl.nArgsMax = max
+	return nil
+}
+
+// SetOptional marks a positional flag as optional i.e. it may be omitted on the command line in
+// which case it keeps its default value. It returns error if the flag is not positional.
+func (fl *Flag) SetOptional(optional bool) error {
+	if !fl.positional {
+		return fmt.Errorf("only positional flags can be marked optional")
+	}
+	fl.optional = optional
+	return nil
+}
+
+// SetSeparator makes the flag split each of its arguments at sep, so that for e.g. "a,b" results in
+// two elements. A sep preceded by a backslash is not split at. Elements of all occurrences of the
+// flag are collected. Array flags take a single argument with a separator unless nargs is set
+// afterwards. It returns error if sep is 0 or the flag's value is not a slice, array or map.
+func (fl *Flag) SetSeparator(sep rune) error {
+	if sep == 0 {
+		return fmt.Errorf("separator cannot be 0")
+	}
+	// a pointer flag can have a separator if the pointed to type can
+	typ := reflect.TypeOf(fl.value.Get())
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || (typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array && typ.Kind() != reflect.Map) {
+		return fmt.Errorf("separator is only supported for slice, array and map types, not '%T'", fl.value.Get())
+	}
+	if nv, ok := fl.value.(nargsValue); ok && fl.nArgs == nv.defaultNArgs() {
+		fl.nArgs = 1
+	}
+	fl.sep = sep
 	return nil
 }
 
+// nargsValue is implemented by Values which require a specific number of arguments by default,
+// for e.g. arrays.
+type nargsValue interface {
+	defaultNArgs() int
+}
+
 func NewFlag(val Value, pos bool, usage string) *Flag {
+	nargs := 1
+	if nv, ok := val.(nargsValue); ok {
+		nargs = nv.defaultNArgs()
+	}
 	return &Flag{
-		nArgs:      1,
+		nArgs:      nargs,
 		value:      val,
 		usage:      usage,
 		positional: pos,
 		defVal:     val.String(),
+		defCopy:    copyPointee(valueTarget(val)),
+	}
+}
+
+// copyPointee returns a copy of the variable pointed to by val. Slices and maps are copied so that
+// later modifications to the variable do not affect the copy. It returns an invalid reflect.Value
+// if val is not a pointer.
+func copyPointee(val interface{}) reflect.Value {
+	ptr := reflect.ValueOf(val)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return reflect.Value{}
+	}
+	return copyReflectValue(ptr.Elem())
+}
+
+func copyReflectValue(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	switch {
+	case v.Kind() == reflect.Slice && !v.IsNil():
+		c.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		reflect.Copy(c, v)
+	case v.Kind() == reflect.Map && !v.IsNil():
+		c.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+		for _, k := range v.MapKeys() {
+			c.SetMapIndex(k, v.MapIndex(k))
+		}
+	default:
+		c.Set(v)
+	}
+	return c
+}
+
+// resetValue restores the variable pointed to by the flag's value to its default.
+func (fl *Flag) resetValue() {
+	if r, ok := fl.value.(interface{ reset() }); ok {
+		r.reset()
+		return
+	}
+	if !fl.defCopy.IsValid() {
+		return
 	}
+	reflect.ValueOf(valueTarget(fl.value)).Elem().Set(copyReflectValue(fl.defCopy))
 }
 
 func NewBoolFlag(val *bool, pos bool, usage string) *Flag {
@@ -73,6 +267,208 @@ func NewIntListFlag(val *[]int, pos bool, usage string) *Flag {
 	return NewFlag(newIntListValue(val), pos, usage)
 }
 
+func NewInt8Flag(val *int8, pos bool, usage string) *Flag {
+	return NewFlag(newInt8Value(val), pos, usage)
+}
+
+func NewInt8ListFlag(val *[]int8, pos bool, usage string) *Flag {
+	return NewFlag(newInt8ListValue(val), pos, usage)
+}
+
+func NewInt16Flag(val *int16, pos bool, usage string) *Flag {
+	return NewFlag(newInt16Value(val), pos, usage)
+}
+
+func NewInt16ListFlag(val *[]int16, pos bool, usage string) *Flag {
+	return NewFlag(newInt16ListValue(val), pos, usage)
+}
+
+func NewInt32Flag(val *int32, pos bool, usage string) *Flag {
+	return NewFlag(newInt32Value(val), pos, usage)
+}
+
+func NewInt32ListFlag(val *[]int32, pos bool, usage string) *Flag {
+	return NewFlag(newInt32ListValue(val), pos, usage)
+}
+
+func NewInt64Flag(val *int64, pos bool, usage string) *Flag {
+	return NewFlag(newInt64Value(val), pos, usage)
+}
+
+func NewInt64ListFlag(val *[]int64, pos bool, usage string) *Flag {
+	return NewFlag(newInt64ListValue(val), pos, usage)
+}
+
+func NewUintFlag(val *uint, pos bool, usage string) *Flag {
+	return NewFlag(newUintValue(val), pos, usage)
+}
+
+func NewUintListFlag(val *[]uint, pos bool, usage string) *Flag {
+	return NewFlag(newUintListValue(val), pos, usage)
+}
+
+func NewUint8Flag(val *uint8, pos bool, usage string) *Flag {
+	return NewFlag(newUint8Value(val), pos, usage)
+}
+
+func NewUint8ListFlag(val *[]uint8, pos bool, usage string) *Flag {
+	return NewFlag(newUint8ListValue(val), pos, usage)
+}
+
+func NewUint16Flag(val *uint16, pos bool, usage string) *Flag {
+	return NewFlag(newUint16Value(val), pos, usage)
+}
+
+func NewUint16ListFlag(val *[]uint16, pos bool, usage string) *Flag {
+	return NewFlag(newUint16ListValue(val), pos, usage)
+}
+
+func NewUint32Flag(val *uint32, pos bool, usage string) *Flag {
+	return NewFlag(newUint32Value(val), pos, usage)
+}
+
+func NewUint32ListFlag(val *[]uint32, pos bool, usage string) *Flag {
+	return NewFlag(newUint32ListValue(val), pos, usage)
+}
+
+func NewUint64Flag(val *uint64, pos bool, usage string) *Flag {
+	return NewFlag(newUint64Value(val), pos, usage)
+}
+
+func NewUint64ListFlag(val *[]uint64, pos bool, usage string) *Flag {
+	return NewFlag(newUint64ListValue(val), pos, usage)
+}
+
+func NewUintptrFlag(val *uintptr, pos bool, usage string) *Flag {
+	return NewFlag(newUintptrValue(val), pos, usage)
+}
+
+func NewUintptrListFlag(val *[]uintptr, pos bool, usage string) *Flag {
+	return NewFlag(newUintptrListValue(val), pos, usage)
+}
+
+func NewDurationFlag(val *time.Duration, pos bool, usage string) *Flag {
+	return NewFlag(newDurationValue(val), pos, usage)
+}
+
+func NewDurationListFlag(val *[]time.Duration, pos bool, usage string) *Flag {
+	return NewFlag(newDurationListValue(val), pos, usage)
+}
+
+// NewTimeFlag creates a flag whose arguments are parsed as per layout, for e.g. time.RFC3339.
+func NewTimeFlag(val *time.Time, layout string, pos bool, usage string) *Flag {
+	tv := newTimeValue(val)
+	tv.setLayout(layout)
+	return NewFlag(tv, pos, usage)
+}
+
+// NewTimeListFlag creates a flag whose arguments are parsed as per layout, for e.g. time.RFC3339.
+func NewTimeListFlag(val *[]time.Time, layout string, pos bool, usage string) *Flag {
+	tlv := newTimeListValue(val)
+	tlv.setLayout(layout)
+	return NewFlag(tlv, pos, usage)
+}
+
+// NewStringMapFlag creates a flag whose arguments are key=value pairs, for e.g. "--label env=prod".
+func NewStringMapFlag(val *map[string]string, pos bool, usage string) *Flag {
+	mv, _ := newMapValue(reflect.ValueOf(val).Elem())
+	return NewFlag(mv, pos, usage)
+}
+
+func NewIPFlag(val *net.IP, pos bool, usage string) *Flag {
+	return NewFlag(newIPValue(val), pos, usage)
+}
+
+func NewIPListFlag(val *[]net.IP, pos bool, usage string) *Flag {
+	return newListFlag(val, nil, pos, usage)
+}
+
+// NewIPNetFlag creates a flag whose argument is given in CIDR notation like "192.168.0.0/16".
+func NewIPNetFlag(val *net.IPNet, pos bool, usage string) *Flag {
+	return NewFlag(newIPNetValue(val), pos, usage)
+}
+
+// NewIPNetListFlag creates a flag whose arguments are given in CIDR notation like "192.168.0.0/16".
+func NewIPNetListFlag(val *[]net.IPNet, pos bool, usage string) *Flag {
+	return newListFlag(val, nil, pos, usage)
+}
+
+// NewHostPortFlag creates a flag whose argument is a "host:port" endpoint. If defPort is not empty
+// then the port may be omitted in which case defPort is used.
+func NewHostPortFlag(val *HostPort, defPort string, pos bool, usage string) *Flag {
+	hpv := newHostPortValue(val)
+	hpv.setDefPort(defPort)
+	return NewFlag(hpv, pos, usage)
+}
+
+// NewHostPortListFlag is like NewHostPortFlag but for a list of endpoints.
+func NewHostPortListFlag(val *[]HostPort, defPort string, pos bool, usage string) *Flag {
+	return newListFlag(val, map[string]string{defPortKey: defPort}, pos, usage)
+}
+
+// NewURLFlag creates a flag whose argument is a URL. If schemes are given then the URL must have
+// one of them.
+func NewURLFlag(val *url.URL, schemes []string, pos bool, usage string) *Flag {
+	uv := newURLValue(val)
+	uv.setSchemes(schemes)
+	return NewFlag(uv, pos, usage)
+}
+
+// NewURLListFlag is like NewURLFlag but for a list of URLs.
+func NewURLListFlag(val *[]*url.URL, schemes []string, pos bool, usage string) *Flag {
+	var keyValues map[string]string
+	if len(schemes) > 0 {
+		keyValues = map[string]string{schemesKey: strings.Join(schemes, optNameSep)}
+	}
+	return newListFlag(val, keyValues, pos, usage)
+}
+
+// NewByteSizeFlag creates a flag whose argument is a byte size like "512KiB", "10MB" or "1.5GiB".
+func NewByteSizeFlag(val *int64, pos bool, usage string) *Flag {
+	uv, _ := newUnitsValue(newInt64Value(val), unitsBytes)
+	return NewFlag(uv, pos, usage)
+}
+
+// NewQuantityFlag creates a flag whose argument is a number with an optional SI suffix like "2k" or
+// "3.5M".
+func NewQuantityFlag(val *float64, pos bool, usage string) *Flag {
+	uv, _ := newUnitsValue(newFloat64Value(val), unitsSI)
+	return NewFlag(uv, pos, usage)
+}
+
+// NewPathFlag creates a flag whose argument is a file system path which must satisfy check. A
+// leading "~" is expanded to the user's home directory and if abs is true then the path is made
+// absolute.
+func NewPathFlag(val *string, check PathCheck, abs bool, pos bool, usage string) *Flag {
+	pv, _ := newPathValue(newStringValue(val), check, abs)
+	return NewFlag(pv, pos, usage)
+}
+
+// NewPathListFlag is like NewPathFlag but for a list of paths.
+func NewPathListFlag(val *[]string, check PathCheck, abs bool, pos bool, usage string) *Flag {
+	pv, _ := newPathValue(newStringListValue(val), check, abs)
+	return NewFlag(pv, pos, usage)
+}
+
+// newListFlag creates a flag for val, which must be a pointer to a slice of a supported type, with
+// keyValues applied to each element's Value.
+func newListFlag(val interface{}, keyValues map[string]string, pos bool, usage string) *Flag {
+	sv, _ := newSliceValue(reflect.ValueOf(val).Elem())
+	listVal, _ := applyValueKeys(sv, keyValues)
+	return NewFlag(listVal, pos, usage)
+}
+
+// NewTextFlag creates a flag for a type implementing encoding.TextUnmarshaler like net.IP or
+// big.Int.
+func NewTextFlag(val encoding.TextUnmarshaler, pos bool, usage string) *Flag {
+	return NewFlag(newTextValue(val), pos, usage)
+}
+
+// NewStdFlag creates a flag for a type implementing the standard library's flag.Value interface.
+func NewStdFlag(val stdflag.Value, pos bool, usage string) *Flag {
+	return NewFlag(newStdFlagValue(val), pos, usage)
+}
+
 func NewFloat64Flag(val *float64, pos bool, usage string) *Flag {
 	return NewFlag(newFloat64Value(val), pos, usage)
 }
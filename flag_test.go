@@ -38,3 +38,129 @@ func Test_SetNArgs_WithOptionalFlag(t *testing.T) {
 		t.Errorf("Testing: Flag.SetNArgs(10); Expected: no error and %#v; Got: error %v, %#v", expected, err, *optFlag)
 	}
 }
+
+func Test_SetNArgsRange(t *testing.T) {
+	testVar := 100
+
+	posFlag := NewIntFlag(&testVar, true, "")
+	optFlag := NewIntFlag(&testVar, false, "")
+	invalid := []struct {
+		fl       *Flag
+		min, max int
+	}{
+		{optFlag, -1, 2},
+		{optFlag, 3, 2},
+		{posFlag, 0, 2},
+		{posFlag, 0, 0},
+	}
+	for _, input := range invalid {
+		if err := input.fl.SetNArgsRange(input.min, input.max); err == nil {
+			t.Errorf("Testing: Flag.SetNArgsRange(%d, %d); Expected: error; Got: no error", input.min, input.max)
+		}
+	}
+
+	valid := []struct {
+		fl               *Flag
+		min, max         int
+		expMin, expMax   int
+		expectedIsSwitch bool
+	}{
+		{optFlag, 0, 0, 0, 0, true},
+		{optFlag, 0, 3, 0, 3, false},
+		{optFlag, 2, -1, 2, -1, false},
+		{optFlag, 2, 2, 2, 2, false},
+		{posFlag, 1, 4, 1, 4, false},
+	}
+	for _, input := range valid {
+		if err := input.fl.SetNArgsRange(input.min, input.max); err != nil {
+			t.Errorf("Testing: Flag.SetNArgsRange(%d, %d); Expected: no error; Got: %v", input.min, input.max, err)
+		}
+		if min, max := input.fl.argRange(); min != input.expMin || max != input.expMax {
+			t.Errorf("Testing: Flag.SetNArgsRange(%d, %d); Expected: range (%d, %d); Got: (%d, %d)", input.min,
+				input.max, input.expMin, input.expMax, min, max)
+		}
+		if input.fl.isSwitch() != input.expectedIsSwitch {
+			t.Errorf("Testing: Flag.SetNArgsRange(%d, %d); Expected: isSwitch() %v; Got: %v", input.min,
+				input.max, input.expectedIsSwitch, input.fl.isSwitch())
+		}
+	}
+}
+
+func Test_SetOptional(t *testing.T) {
+	testVar := 100
+
+	optFlag := NewIntFlag(&testVar, false, "")
+	if err := optFlag.SetOptional(true); err == nil {
+		t.Errorf("Testing: Flag.SetOptional(true) on optional flag; Expected: error; Got: no error")
+	}
+
+	posFlag := NewIntFlag(&testVar, true, "")
+	if err := posFlag.SetOptional(true); err != nil {
+		t.Errorf("Testing: Flag.SetOptional(true); Expected: no error; Got: %v", err)
+	}
+	if min, max := posFlag.argRange(); min != 0 || max != 1 {
+		t.Errorf("Testing: Flag.SetOptional(true); Expected: range (0, 1); Got: (%d, %d)", min, max)
+	}
+}
+
+func Test_SetSeparator(t *testing.T) {
+	testVar := 100
+	for _, fl := range []*Flag{NewIntFlag(&testVar, false, ""), NewBoolFlag(new(bool), false, "")} {
+		if err := fl.SetSeparator(','); err == nil {
+			t.Errorf("Testing: Flag.SetSeparator(',') on %T flag; Expected: error; Got: no error", fl.value.Get())
+		}
+	}
+
+	listFlag := NewIntListFlag(new([]int), false, "")
+	if err := listFlag.SetSeparator(0); err == nil {
+		t.Errorf("Testing: Flag.SetSeparator(0); Expected: error; Got: no error")
+	}
+	if err := listFlag.SetSeparator(':'); err != nil {
+		t.Errorf("Testing: Flag.SetSeparator(':'); Expected: no error; Got: %v", err)
+	}
+	if listFlag.Separator() != ':' {
+		t.Errorf("Testing: Flag.Separator(); Expected: ':'; Got: %q", listFlag.Separator())
+	}
+
+	// array flags take a single argument once a separator is set
+	arr := [3]int{}
+	val, _ := newValue(&arr)
+	arrFlag := NewFlag(val, false, "")
+	if err := arrFlag.SetSeparator(','); err != nil {
+		t.Errorf("Testing: Flag.SetSeparator(',') on array flag; Expected: no error; Got: %v", err)
+	}
+	if arrFlag.NArgs() != 1 {
+		t.Errorf("Testing: Flag.SetSeparator(',') on array flag; Expected: nargs 1; Got: %d", arrFlag.NArgs())
+	}
+}
+
+func Test_Flag_Accessors(t *testing.T) {
+	testVar := 100
+	fl := NewIntFlag(&testVar, false, "usage of flag")
+	fl.SetNArgsRange(2, 4)
+	fs := NewFlagSet()
+	if err := fs.Add(fl, "--flag", "-f"); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	if names := fl.Names(); !reflect.DeepEqual(names, []string{"--flag", "-f"}) {
+		t.Errorf("Testing: Flag.Names(); Expected: [--flag -f]; Got: %q", names)
+	}
+	if fl.Usage() != "usage of flag" {
+		t.Errorf("Testing: Flag.Usage(); Expected: %q; Got: %q", "usage of flag", fl.Usage())
+	}
+	if fl.NArgs() != 2 {
+		t.Errorf("Testing: Flag.NArgs(); Expected: 2; Got: %d", fl.NArgs())
+	}
+	if min, max := fl.NArgsRange(); min != 2 || max != 4 {
+		t.Errorf("Testing: Flag.NArgsRange(); Expected: (2, 4); Got: (%d, %d)", min, max)
+	}
+	if fl.Positional() || fl.Optional() {
+		t.Errorf("Testing: Flag.Positional(), Flag.Optional(); Expected: false, false; Got: %v, %v", fl.Positional(), fl.Optional())
+	}
+	if fl.DefValue() != "100" {
+		t.Errorf("Testing: Flag.DefValue(); Expected: %q; Got: %q", "100", fl.DefValue())
+	}
+	if fl.Value().Get() != 100 {
+		t.Errorf("Testing: Flag.Value(); Expected: Value of 100; Got: %v", fl.Value().Get())
+	}
+}
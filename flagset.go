@@ -18,10 +18,20 @@ const (
 	kvSep            rune   = '='
 	kvPairSep        rune   = ','
 	optNameSep       string = ":"
+	nargsRangeSep    string = ".."
 	defaultOptPrefix string = "-"
 	nameKey          string = "name"
 	usageKey         string = "usage"
 	nargsKey         string = "nargs"
+	optionalKey      string = "optional"
+	layoutKey        string = "layout"
+	dupKeysKey       string = "dupkeys"
+	sepKey           string = "sep"
+	defPortKey       string = "defport"
+	schemesKey       string = "schemes"
+	unitsKey         string = "units"
+	pathKey          string = "path"
+	absKey           string = "abs"
 	helpShort        string = "-h"
 	helpLong         string = "--help"
 	packageTag       string = "flagparse"
@@ -29,9 +39,32 @@ const (
 
 var validKVs = map[string]*regexp.Regexp{
 	usageKey: regexp.MustCompile(fmt.Sprintf(`^%s%c(.+)$`, usageKey, kvSep)),
-	nargsKey: regexp.MustCompile(fmt.Sprintf(`^%s%c(-?[[:digit:]]+)$`, nargsKey, kvSep)),
+	nargsKey: regexp.MustCompile(fmt.Sprintf(`^%s%c(-?[[:digit:]]+|[[:digit:]]+%s[[:digit:]]+)$`, nargsKey,
+		kvSep, regexp.QuoteMeta(nargsRangeSep))),
+	optionalKey: regexp.MustCompile(fmt.Sprintf(`^%s%c(true|false)$`, optionalKey, kvSep)),
 	nameKey: regexp.MustCompile(fmt.Sprintf(`^%s%c([-[:alnum:]]+(%s[-[:alnum:]]+)*)$`, nameKey,
 		kvSep, optNameSep)),
+	layoutKey:  regexp.MustCompile(fmt.Sprintf(`^%s%c(.+)$`, layoutKey, kvSep)),
+	dupKeysKey: regexp.MustCompile(fmt.Sprintf(`^%s%c(error|last)$`, dupKeysKey, kvSep)),
+	sepKey:     regexp.MustCompile(fmt.Sprintf(`^%s%c(.)$`, sepKey, kvSep)),
+	defPortKey: regexp.MustCompile(fmt.Sprintf(`^%s%c([-[:alnum:]]+)$`, defPortKey, kvSep)),
+	schemesKey: regexp.MustCompile(fmt.Sprintf(`^%s%c([-+.[:alnum:]]+(%s[-+.[:alnum:]]+)*)$`, schemesKey,
+		kvSep, optNameSep)),
+	unitsKey: regexp.MustCompile(fmt.Sprintf(`^%s%c(%s|%s)$`, unitsKey, kvSep, unitsBytes, unitsSI)),
+	pathKey: regexp.MustCompile(fmt.Sprintf(`^%s%c(%s|%s|%s|%s|%s)$`, pathKey, kvSep, PathAny,
+		PathExistingFile, PathExistingDir, PathNotExist, PathParentExist)),
+	absKey: regexp.MustCompile(fmt.Sprintf(`^%s%c(true|false)$`, absKey, kvSep)),
+}
+
+// valueKeys are the keys of validKVs which configure a flag's Value rather than the flag itself.
+var valueKeys = map[string]bool{
+	layoutKey:  true,
+	dupKeysKey: true,
+	defPortKey: true,
+	schemesKey: true,
+	unitsKey:   true,
+	pathKey:    true,
+	absKey:     true,
 }
 
 type ErrHelpInvoked struct{}
@@ -52,11 +85,32 @@ type FlagSet struct {
 	CmdArgs         []string
 	posFlags        []posWithName
 	optFlags        map[string]*Flag
-	// following fields are used for state changes during parsing
-	iPos        int
-	curFlag     *Flag
-	curFlagName string
-	curFlagArgs []string
+	srcType         reflect.Type   // type of struct the FlagSet was created from, if any
+	actual          map[*Flag]bool // flags set by the most recent parse
+
+	// StopAtFirstPositional stops processing optional flags at the first argument meant for a
+	// positional flag. That argument and all arguments after it, even the ones looking like
+	// optional flags, are then treated as arguments for positional flags (POSIXLY_CORRECT style).
+	// By default optional flags may appear anywhere on the command line.
+	StopAtFirstPositional bool
+
+	// AllowAbbrev allows long optional flags to be abbreviated to any unique prefix, for e.g.
+	// "--verb" for "--verbose". By default optional flag names must match exactly.
+	AllowAbbrev bool
+
+	// CollectErrors makes parsing continue after recoverable errors like an unrecognized flag or an
+	// invalid value so that every problem is reported at once as an ErrorList. By default parsing
+	// stops at the first error.
+	CollectErrors bool
+
+	// Exit is called with the appropriate exit code when parsing fails and ContinueOnError is not
+	// set. If nil, os.Exit is used. A hook which returns lets the Parse methods return the error
+	// as if ContinueOnError was set.
+	Exit func(code int)
+	// HelpExitCode and ErrorExitCode are the exit codes used when help is invoked and when parsing
+	// fails respectively.
+	HelpExitCode  int
+	ErrorExitCode int
 }
 
 func NewFlagSet() *FlagSet {
@@ -65,30 +119,34 @@ func NewFlagSet() *FlagSet {
 		usageOut: os.Stderr,
 		name:     os.Args[0],
 		CmdArgs:  os.Args[1:],
+
+		HelpExitCode:  1,
+		ErrorExitCode: 2,
 	}
 	return fs
 }
 
 func NewFlagSetFrom(src interface{}) (*FlagSet, error) {
 	if src == nil {
-		return nil, fmt.Errorf("src cannot be nil")
+		return nil, &DefinitionError{Err: fmt.Errorf("src cannot be nil")}
 	}
 	// get Type data of src, verify that it is of pointer type
 	srcTyp := reflect.TypeOf(src)
 	if srcTyp.Kind() != reflect.Ptr {
-		return nil, fmt.Errorf("src must be a pointer to struct")
+		return nil, &DefinitionError{Err: fmt.Errorf("src must be a pointer to struct")}
 	}
 
 	// get Type data of the actual struct pointed by the pointer,
 	// verify that it is a struct
 	srcTyp = srcTyp.Elem()
 	if srcTyp.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("src must be a pointer to struct")
+		return nil, &DefinitionError{Err: fmt.Errorf("src must be a pointer to struct")}
 	}
 
 	srcVal := reflect.ValueOf(src).Elem()
 
 	fs := NewFlagSet()
+	fs.srcType = srcTyp
 	// iterate over all fields of the struct, parse the value of 'packageTag'
 	// and create flags accordingly. Skip any field not having the tag.
 	for i := 0; i < srcTyp.NumField(); i++ {
@@ -102,18 +160,35 @@ func NewFlagSetFrom(src interface{}) (*FlagSet, error) {
 
 		val, err := newValue(fieldVal.Addr().Interface())
 		if err != nil {
-			return nil, fmt.Errorf("Error while creating flag from field '%s': %s", fieldType.Name, err)
+			return nil, &DefinitionError{Field: fieldType.Name, Err: err}
 		}
 
-		err = fs.addFlagFromTag(val, tagValue, fieldType.Name)
-		if err != nil {
-			return nil, fmt.Errorf("Error while creating flag from field '%s': %s", fieldType.Name, err)
+		err = fs.addFlagFromTag(val, tagValue, fieldType.Name, fieldBinder(i, tagValue))
+		if de, ok := err.(*DefinitionError); ok {
+			de.Field = fieldType.Name
+			return nil, de
+		} else if err != nil {
+			return nil, &DefinitionError{Field: fieldType.Name, Err: err}
 		}
 	}
 
 	return fs, nil
 }
 
+// fieldBinder returns a function which creates the Value for the i'th field of a struct, configured
+// as per the field's tagValue.
+func fieldBinder(i int, tagValue string) func(reflect.Value) (Value, error) {
+	// tagValue has already been validated while creating the flag
+	keyValues, _ := parseKVs(tagValue)
+	return func(dst reflect.Value) (Value, error) {
+		val, err := newValue(dst.Field(i).Addr().Interface())
+		if err != nil {
+			return nil, err
+		}
+		return applyValueKeys(val, keyValues)
+	}
+}
+
 func validPosName(name string) bool {
 	return regexp.MustCompile(`^[[:alnum:]][-[:alnum:]]+$`).MatchString(name)
 }
@@ -128,37 +203,77 @@ func (fs *FlagSet) Add(fl *Flag, name string, optNames ...string) error {
 	}
 	if fl.positional {
 		if !validPosName(name) {
-			return fmt.Errorf("%q is not a valid positional flag name", name)
+			return &DefinitionError{Flag: name, Err: fmt.Errorf("%q is not a valid positional flag name",
+				name)}
 		}
 		// check for duplicate name
 		for _, v := range fs.posFlags {
 			if name == v.name {
-				return fmt.Errorf("positional flag with name %q already exists", name)
+				return &DefinitionError{Flag: name,
+					Err: fmt.Errorf("positional flag with name %q already exists", name)}
 			}
 		}
 		fs.posFlags = append(fs.posFlags, posWithName{name, fl})
+		fl.names = []string{name}
 	} else {
 		names := []string{name}
 		names = append(names, optNames...)
 		for _, nm := range names {
 			if nm == helpShort || nm == helpLong {
-				return fmt.Errorf("flag names %s,%s are reserved", helpShort, helpLong)
+				return &DefinitionError{Flag: nm,
+					Err: fmt.Errorf("flag names %s,%s are reserved", helpShort, helpLong)}
 			}
 			if !validOptName(nm) {
-				return fmt.Errorf("%q is not a valid optional flag name", nm)
+				return &DefinitionError{Flag: nm, Err: fmt.Errorf("%q is not a valid optional flag name",
+					nm)}
 			}
 			// check for duplicate name
 			for v := range fs.optFlags {
 				if nm == v {
-					return fmt.Errorf("optional flag with name %q already exists", nm)
+					return &DefinitionError{Flag: nm,
+						Err: fmt.Errorf("optional flag with name %q already exists", nm)}
 				}
 			}
 			fs.optFlags[nm] = fl
 		}
+		fl.names = names
+	}
+	return nil
+}
+
+// Lookup returns the flag with the given name, which can be the name of a positional flag or any
+// of the names of an optional flag. It returns nil if no such flag exists.
+func (fs *FlagSet) Lookup(name string) *Flag {
+	if fl, ok := fs.optFlags[name]; ok {
+		return fl
+	}
+	for _, p := range fs.posFlags {
+		if p.name == name {
+			return p.flag
+		}
 	}
 	return nil
 }
 
+// Visit calls fn for each flag which was set by the most recent call to one of the Parse methods,
+// except ParseInto. Positional flags are visited in the order they were added followed by optional
+// flags sorted by name.
+func (fs *FlagSet) Visit(fn func(*Flag)) {
+	for _, fl := range fs.allFlags() {
+		if fs.actual[fl] {
+			fn(fl)
+		}
+	}
+}
+
+// VisitAll calls fn for each flag in the FlagSet, set or not. Positional flags are visited in the
+// order they were added followed by optional flags sorted by name.
+func (fs *FlagSet) VisitAll(fn func(*Flag)) {
+	for _, fl := range fs.allFlags() {
+		fn(fl)
+	}
+}
+
 func splitKVs(src string, sep rune) []string {
 	backSlash := '\\'
 	parts := make([]string, 0)
@@ -205,11 +320,37 @@ func parseKVs(tagValue string) (map[string]string, error) {
 	return kvs, nil
 }
 
-func (fs *FlagSet) addFlagFromTag(value Value, tagValue string, fieldName string) error {
-	keyValues, err := parseKVs(tagValue)
+// addFlagFromTag creates a flag for value as specified by tagValue and adds it to fs. bind, if not
+// nil, is used by ParseInto to create the flag's Value for another instance of the struct.
+func (fs *FlagSet) addFlagFromTag(value Value, tagValue string, fieldName string,
+	bind func(reflect.Value) (Value, error)) error {
+	fl, names, err := newFlagFromTag(value, tagValue, fieldName)
 	if err != nil {
 		return err
 	}
+	fl.bind = bind
+	return fs.Add(fl, names[0], names[1:]...)
+}
+
+// newFlagFromTag creates a flag for value as specified by tagValue. It also returns the names with
+// which the flag should be added to a FlagSet.
+func newFlagFromTag(value Value, tagValue string, fieldName string) (*Flag, []string, error) {
+	keyValues, err := parseKVs(tagValue)
+	if err != nil {
+		return nil, nil, &DefinitionError{Err: err}
+	}
+
+	names := strings.Split(keyValues[nameKey], optNameSep)
+	// if no name is given then use field's name in lower case
+	if names[0] == "" {
+		names[0] = strings.ToLower(fieldName)
+	}
+
+	// configure value before creating the flag so that the default is formatted accordingly
+	value, err = applyValueKeys(value, keyValues)
+	if err != nil {
+		return nil, nil, &DefinitionError{Flag: names[0], Err: err}
+	}
 
 	var fl *Flag
 
@@ -220,134 +361,160 @@ func (fs *FlagSet) addFlagFromTag(value Value, tagValue string, fieldName string
 		fl = NewFlag(value, true, keyValues[usageKey])
 	}
 
+	// set separator before nargs so that nargs given in the tag takes precedence
+	if keyValues[sepKey] != "" {
+		if err := fl.SetSeparator([]rune(keyValues[sepKey])[0]); err != nil {
+			return nil, nil, &DefinitionError{Flag: names[0], Err: err}
+		}
+	}
+
 	// set nargs for the flag
 	if keyValues[nargsKey] != "" {
-		nargs, err := strconv.ParseInt(keyValues[nargsKey], 0, strconv.IntSize)
-		if err != nil {
-			return formatParseError(keyValues[nargsKey], fmt.Sprintf("%T", int(1)), err)
-		}
-		err = fl.SetNArgs(int(nargs))
-		if err != nil {
-			return err
+		if err := setNArgsFromTag(fl, keyValues[nargsKey]); err != nil {
+			return nil, nil, &DefinitionError{Flag: names[0], Err: err}
 		}
 	}
 
-	names := strings.Split(keyValues[nameKey], optNameSep)
-	// if no name is given then use field's name in lower case
-	if names[0] == "" {
-		names[0] = strings.ToLower(fieldName)
+	// mark positional flag as optional
+	if keyValues[optionalKey] != "" {
+		optional, _ := strconv.ParseBool(keyValues[optionalKey])
+		if err := fl.SetOptional(optional); err != nil {
+			return nil, nil, &DefinitionError{Flag: names[0], Err: err}
+		}
 	}
-	return fs.Add(fl, names[0], names[1:]...)
-}
 
-func (fs *FlagSet) writeAndCloseFlag() error {
-	if err := fs.curFlag.value.Set(fs.curFlagArgs...); err != nil {
-		return err
-	}
-	fs.curFlagName = ""
-	fs.curFlag = nil
-	fs.curFlagArgs = fs.curFlagArgs[:0]
-	return nil
+	return fl, names, nil
 }
 
-func (fs *FlagSet) processArg(arg string) error {
-	if arg != fs.curFlagName {
-		fs.curFlagArgs = append(fs.curFlagArgs, arg)
+// setNArgsFromTag sets nargs of fl from the value of nargs key which is either a single integer or
+// a range of the form min..max.
+func setNArgsFromTag(fl *Flag, value string) error {
+	bounds := strings.Split(value, nargsRangeSep)
+	nargs := make([]int, len(bounds))
+	for i, b := range bounds {
+		n, err := strconv.ParseInt(b, 0, strconv.IntSize)
+		if err != nil {
+			return formatParseError(b, fmt.Sprintf("%T", int(1)), err)
+		}
+		nargs[i] = int(n)
 	}
-	if len(fs.curFlagArgs) == fs.curFlag.nArgs {
-		return fs.writeAndCloseFlag()
+	if len(nargs) == 2 {
+		return fl.SetNArgsRange(nargs[0], nargs[1])
 	}
-	return nil
+	return fl.SetNArgs(nargs[0])
 }
 
-func (fs *FlagSet) closeFlag() error {
-	if fs.curFlag.nArgs < 0 {
-		if len(fs.curFlagArgs) < 1 {
-			return fmt.Errorf("flag %s expects at least one argument", fs.curFlagName)
-		}
-		if err := fs.writeAndCloseFlag(); err != nil {
-			return err
+// lookupOptName returns the name of the optional flag referred to by arg. If AllowAbbrev is set
+// then arg may also be a unique prefix of a long optional flag name. It returns an empty string if
+// no flag matches arg and error if arg, found at index pos in CmdArgs, is an ambiguous prefix.
+func (fs *FlagSet) lookupOptName(arg string, pos int) (string, error) {
+	if _, ok := fs.optFlags[arg]; ok {
+		return arg, nil
+	}
+	longPrefix := defaultOptPrefix + defaultOptPrefix
+	if !fs.AllowAbbrev || !strings.HasPrefix(arg, longPrefix) || len(arg) == len(longPrefix) {
+		return "", nil
+	}
+	var names []string
+	for name := range fs.optFlags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var matches []string
+	seen := make(map[*Flag]bool)
+	for _, name := range names {
+		// aliases of the same flag do not make a prefix ambiguous
+		if fl := fs.optFlags[name]; strings.HasPrefix(name, arg) && !seen[fl] {
+			seen[fl] = true
+			matches = append(matches, name)
 		}
-	} else {
-		return fmt.Errorf("flag %s expects %d arguments, given %d", fs.curFlagName,
-			fs.curFlag.nArgs, len(fs.curFlagArgs))
 	}
-	return nil
-}
-
-func (fs *FlagSet) openFlag(curArg string, pos bool) error {
-	if pos {
-		fs.curFlagName = fs.posFlags[fs.iPos].name
-		fs.curFlag = fs.posFlags[fs.iPos].flag
-	} else {
-		fs.curFlagName = curArg
-		fs.curFlag = fs.optFlags[fs.curFlagName]
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		return matches[0], nil
 	}
-	return fs.processArg(curArg)
+	return "", &UnknownFlagError{Name: arg, Pos: pos, Ambiguous: true, Candidates: matches}
 }
 
-func (fs *FlagSet) parse() error {
-	for _, curArg := range fs.CmdArgs {
-		// does it looks like an optional flag?
-		if strings.HasPrefix(curArg, defaultOptPrefix) {
-			// is it a help flag?
-			if curArg == helpShort || curArg == helpLong {
-				return &ErrHelpInvoked{}
+// editDistance returns the Levenshtein distance between a and b i.e. the minimum number of single
+// character insertions, deletions and substitutions required to change a into b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
 			}
-			// if this is not a known flag then return error
-			if _, ok := fs.optFlags[curArg]; !ok {
-				return fmt.Errorf("unrecognized flag %s", curArg)
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
 			}
-			// since this is a know flag so before starting  to process it, try closing the current
-			// pos/opt flag if any
-			if fs.curFlagName != "" {
-				if err := fs.closeFlag(); err != nil {
-					return err
-				}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
 			}
-			// since this is a known opt flag, no flag is opened currently, open this flag for
-			// processing
-			if err := fs.openFlag(curArg, false); err != nil {
-				return err
-			}
-			continue
 		}
-		// if there is a pos/opt flag open then process current argument for it
-		if fs.curFlagName != "" {
-			if err := fs.processArg(curArg); err != nil {
-				return err
-			}
-			continue
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// suggestOptNames returns the optional flag names closest to arg, in sorted order. Only names
+// within a small edit distance of arg are considered, which must be less than the length of the
+// name without its prefix so that for e.g. "-x" does not suggest "-o". If trimPrefix is true then
+// arg, a leftover argument, is compared against the names without their prefix, this helps when the
+// prefix itself was forgotten. No names are suggested for leftover arguments shorter than
+// minSuggestLen.
+func (fs *FlagSet) suggestOptNames(arg string, trimPrefix bool) []string {
+	if trimPrefix && len(arg) < minSuggestLen {
+		return nil
+	}
+	maxDist := len(arg) / 3
+	if maxDist < 1 {
+		maxDist = 1
+	}
+	var suggestions []string
+	for name := range fs.optFlags {
+		candidate := name
+		if trimPrefix {
+			candidate = strings.TrimLeft(name, defaultOptPrefix)
 		}
-		// since there is no pos/opt flag open see if there are any pos flags that can be opened
-		// for processing this argument
-		if fs.iPos < len(fs.posFlags) {
-			if err := fs.openFlag(curArg, true); err != nil {
-				return err
-			}
-			fs.iPos++
+		dist := editDistance(arg, candidate)
+		if dist > maxDist || dist >= len(strings.TrimLeft(name, defaultOptPrefix)) {
 			continue
 		}
-		// since all pos/opt flags have been processed, current argument is unwanted/unrecognized
-		return fmt.Errorf("unrecognized argument: %s", curArg)
-	}
-	if fs.curFlagName != "" {
-		if err := fs.closeFlag(); err != nil {
-			return err
+		// keep only the closest names
+		if dist < maxDist {
+			maxDist = dist
+			suggestions = suggestions[:0]
 		}
+		suggestions = append(suggestions, name)
 	}
-	if fs.iPos < len(fs.posFlags) {
-		var names []string
-		for _, fl := range fs.posFlags[fs.iPos:] {
-			names = append(names, fl.name)
-		}
-		return fmt.Errorf("arguments are required for these flags: %v", strings.Join(names, ", "))
+	sort.Strings(suggestions)
+	return suggestions
+}
+
+// minSuggestLen is the minimum length of a leftover argument for which flag names are suggested.
+const minSuggestLen = 3
+
+// didYouMean formats suggestions for appending to an error message.
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
 	}
-	return nil
+	return fmt.Sprintf(", did you mean %s?", strings.Join(suggestions, " or "))
 }
 
-func (fs *FlagSet) Parse() error {
-	err := fs.parse()
+// handleParseError prints err along with the usage message and exits unless ContinueOnError is set.
+func (fs *FlagSet) handleParseError(err error) error {
 	if err == nil {
 		return nil
 	}
@@ -355,18 +522,107 @@ func (fs *FlagSet) Parse() error {
 	var exitCode int
 	switch err.(type) {
 	case *ErrHelpInvoked:
-		exitCode = 1
+		exitCode = fs.HelpExitCode
 	default:
-		exitCode = 2
+		exitCode = fs.ErrorExitCode
 		fmt.Fprintln(fs.usageOut, err)
 	}
 	fs.usage()
 	if !fs.ContinueOnError {
-		os.Exit(exitCode)
+		fs.exit(exitCode)
 	}
 	return err
 }
 
+// exit calls the Exit hook if one is specified, or os.Exit otherwise.
+func (fs *FlagSet) exit(code int) {
+	if fs.Exit == nil {
+		os.Exit(code)
+	} else {
+		fs.Exit(code)
+	}
+}
+
+func (fs *FlagSet) Parse() error {
+	return fs.ParseArgs(fs.CmdArgs)
+}
+
+// ParseArgs is like Parse but processes args instead of CmdArgs. It can be called any number of
+// times on the same FlagSet since all parsing state is reset at the beginning of each call. Values
+// of flags are not reset though, call Reset before ParseArgs for that.
+func (fs *FlagSet) ParseArgs(args []string) error {
+	p := newParser(fs, false)
+	_, err := p.parse(args)
+	fs.actual = p.set
+	return fs.handleParseError(err)
+}
+
+// Reset restores the value of every flag in the FlagSet to its default i.e. the value it had when
+// the flag was created.
+func (fs *FlagSet) Reset() {
+	for _, fl := range fs.allFlags() {
+		fl.resetValue()
+	}
+}
+
+// ParseInto is like ParseArgs but sets the fields of dst instead of the variables the flags were
+// created from. dst must be a pointer to a struct of the same type as the one given to
+// NewFlagSetFrom. Fields of dst for flags not given in args are left untouched. Since neither the
+// FlagSet nor the variables it was created from are modified, ParseInto can be called concurrently
+// with different destinations, for e.g. one per request in a server. Unlike ParseArgs, it neither
+// prints the usage message nor exits on error.
+func (fs *FlagSet) ParseInto(dst interface{}, args []string) error {
+	if fs.srcType == nil {
+		return fmt.Errorf("FlagSet was not created from a struct")
+	}
+	dstVal := reflect.ValueOf(dst)
+	if dstVal.Kind() != reflect.Ptr || dstVal.IsNil() || dstVal.Elem().Type() != fs.srcType {
+		return fmt.Errorf("dst must be a non-nil pointer to %v", fs.srcType)
+	}
+	p := newParser(fs, false)
+	p.values = make(map[*Flag]Value)
+	for _, fl := range fs.allFlags() {
+		if fl.bind == nil {
+			return fmt.Errorf("flag %s was not created from a struct field", fl.names[0])
+		}
+		val, err := fl.bind(dstVal.Elem())
+		if err != nil {
+			return err
+		}
+		p.values[fl] = val
+	}
+	_, err := p.parse(args)
+	return err
+}
+
+// allFlags returns every flag in the FlagSet once, positional flags in the order they were added
+// followed by optional flags sorted by name.
+func (fs *FlagSet) allFlags() []*Flag {
+	var flags []*Flag
+	for _, p := range fs.posFlags {
+		flags = append(flags, p.flag)
+	}
+	var opts []*Flag
+	for _, opt := range fs.optMapToList() {
+		opts = append(opts, opt.fl)
+	}
+	// sort by primary name as the names joined by optMapToList are in no particular order
+	sort.Slice(opts, func(i, j int) bool { return opts[i].names[0] < opts[j].names[0] })
+	return append(flags, opts...)
+}
+
+// ParseKnown is like Parse but it does not fail on unrecognized optional flags or on arguments left
+// over after all positional flags have been satisfied. Instead these are returned in the order in
+// which they appear in CmdArgs so that they can be passed on to another FlagSet or program. Since
+// the number of arguments an unrecognized flag takes is unknown, arguments following it are
+// treated as arguments for positional flags.
+func (fs *FlagSet) ParseKnown() ([]string, error) {
+	p := newParser(fs, true)
+	unknown, err := p.parse(fs.CmdArgs)
+	fs.actual = p.set
+	return unknown, fs.handleParseError(err)
+}
+
 func (fs *FlagSet) SetOutput(w io.Writer) {
 	if w != nil {
 		fs.usageOut = w
@@ -394,14 +650,47 @@ func (fs *FlagSet) optMapToList() []optWithName {
 	return optList
 }
 
+func defaultText(fl *Flag) string {
+	if fl.defVal == "" {
+		return `Default: ""`
+	}
+	return fmt.Sprintf("Default: %s", fl.defVal)
+}
+
+// synopsis returns a one line summary of how to invoke the command. Optional positional flags are
+// shown in brackets and positional flags accepting more than one argument are followed by "...".
+func (fs *FlagSet) synopsis() string {
+	parts := []string{fs.name, fmt.Sprintf("[%s]", helpShort)}
+	if len(fs.optFlags) > 0 {
+		parts = append(parts, "[options]")
+	}
+	for _, p := range fs.posFlags {
+		part := p.name
+		if min, max := p.flag.argRange(); max != 1 || min > 1 {
+			part += "..."
+		}
+		if p.flag.optional {
+			part = fmt.Sprintf("[%s]", part)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
 func (fs *FlagSet) defaultUsage() {
 	out := fs.usageOut
 	fmt.Fprintf(out, "\nUsage of %s:\n", fs.name)
+	fmt.Fprintf(out, "\n  %s\n", fs.synopsis())
 	if fs.Desc != "" {
 		fmt.Fprintf(out, "\n%s\n", fs.Desc)
 	}
 	fmt.Fprint(out, "\nPositional Flags:")
 	for _, fl := range fs.posFlags {
+		if fl.flag.optional {
+			fmt.Fprintf(out, "\n\n  %s  %T\n\t%s. %s", fl.name, fl.flag.value.Get(), fl.flag.usage,
+				defaultText(fl.flag))
+			continue
+		}
 		fmt.Fprintf(out, "\n\n  %s  %T\n\t%s", fl.name, fl.flag.value.Get(), fl.flag.usage)
 	}
 
@@ -412,15 +701,9 @@ func (fs *FlagSet) defaultUsage() {
 			fmt.Fprintf(out, "\n\n  %s\n\t%s", v.name, v.fl.usage)
 			continue
 		}
-		nargs := "Requires: 1 or more arguments"
-		if v.fl.nArgs > 0 {
-			nargs = fmt.Sprintf("Requires: %v argument(s)", v.fl.nArgs)
-		}
-		def := `Default: ""`
-		if v.fl.defVal != "" {
-			def = fmt.Sprintf("Default: %s", v.fl.defVal)
-		}
-		fmt.Fprintf(out, "\n\n  %s  %T\n\t%s. %s. %s", v.name, v.fl.value.Get(), v.fl.usage, nargs, def)
+		nargs := fmt.Sprintf("Requires: %s", v.fl.describeNArgs())
+		fmt.Fprintf(out, "\n\n  %s  %T\n\t%s. %s. %s", v.name, v.fl.value.Get(), v.fl.usage, nargs,
+			defaultText(v.fl))
 	}
 	fmt.Fprint(out, "\n")
 }
@@ -1,11 +1,20 @@
 package flagparse
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"reflect"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 )
 
 func Test_FlagSet_addFlagFromTag_InvalidInput(t *testing.T) {
@@ -16,9 +25,17 @@ func Test_FlagSet_addFlagFromTag_InvalidInput(t *testing.T) {
 		"nargs=99999999999999999999999",
 		"name=pos-flag,nargs=0",
 		"name=--opt:no-prefix",
+		"name=--opt,nargs=4..2",
+		"name=pos-flag,nargs=0..2",
+		"name=--opt,optional=true",
+		"name=--opt,layout=2006-01-02",
+		"name=--opt,dupkeys=error",
+		"name=--opt,sep=:",
+		"name=--opt,defport=80",
+		"name=--opt,schemes=http",
 	}
 	for _, input := range data {
-		if err := fs.addFlagFromTag(testValue, input, ""); err == nil {
+		if err := fs.addFlagFromTag(testValue, input, "", nil); err == nil {
 			t.Errorf("Testing: addFlagFromTag(%q); expected: error; got: no error", input)
 		}
 	}
@@ -30,10 +47,13 @@ func Test_FlagSet_addFlagFromTag_ValidInput(t *testing.T) {
 		"",
 		"name=pos-name,nargs=10",
 		"name=--opt-name,nargs=10,usage=hello",
+		"name=--opt-name,nargs=0..3",
+		"name=pos-name,nargs=1..3",
+		"name=pos-name,optional=true",
 	}
 	for _, input := range data {
 		fs := NewFlagSet()
-		if err := fs.addFlagFromTag(testValue, input, "field-name"); err != nil {
+		if err := fs.addFlagFromTag(testValue, input, "field-name", nil); err != nil {
 			t.Errorf("Testing: addFlagFromTag(%q); expected: error; got: no error", input)
 		}
 	}
@@ -46,6 +66,9 @@ func Test_NewFlagSet(t *testing.T) {
 		usageOut: os.Stderr,
 		name:     os.Args[0],
 		CmdArgs:  os.Args[1:],
+
+		HelpExitCode:  1,
+		ErrorExitCode: 2,
 	}
 	if !reflect.DeepEqual(flagSet, expected) {
 		t.Errorf("Testing: NewFlagSet(); Expected: %#v; Got: %#v", expected, flagSet)
@@ -143,7 +166,7 @@ func Test_NewFlagSetFrom_InvalidInputs(t *testing.T) {
 		new(bool),
 		// Test unsupported field type as input
 		&struct {
-			Field1 int8 `flagparse:""`
+			Field1 complex128 `flagparse:""`
 		}{},
 		// Test error returned from newFlagFromKVs()
 		&struct {
@@ -294,174 +317,1468 @@ func Test_Parse_ValidInputs(t *testing.T) {
 	}
 }
 
-// func Test_Parse_PosFlagWithUnlimitedArgs(t *testing.T) {
-// 	type testCfg struct {
-// 		Pos1 []int `flagparse:"nargs=-1"`
-// 	}
-// 	cfg := &testCfg{}
-
-// 	good := []struct {
-// 		args     []string
-// 		expected *testCfg
-// 	}{
-// 		{
-// 			args:     []string{"11"},
-// 			expected: &testCfg{Pos1: []int{11}},
-// 		},
-// 		{
-// 			args:     []string{"11", "22", "33", "44", "55"},
-// 			expected: &testCfg{Pos1: []int{11, 22, 33, 44, 55}},
-// 		},
-// 	}
-// 	for _, input := range good {
-// 		fs, err := NewFlagSetFrom(cfg)
-// 		if err != nil {
-// 			t.Fatalf("Unexpected error: %q", err)
-// 		}
-// 		f, _ := os.Create(os.DevNull)
-// 		fs.SetOutput(f)
-// 		fs.ContinueOnError = true
-
-// 		fs.CmdArgs = input.args
-// 		if err := fs.Parse(); err != nil {
-// 			t.Errorf("Testing: FlagSet.Parse(); Expected: no error with %q as args; Got: error %q", input.args, err)
-// 		}
-// 		if !reflect.DeepEqual(cfg, input.expected) {
-// 			t.Errorf("Testing: FlagSet.Parse(); Expected: %+v; Got:%+v", input.expected, cfg)
-// 		}
-// 	}
-
-// 	bad := [][]string{{}, {"11", "22", "33", "44abc", "55"}}
-// 	for _, input := range bad {
-// 		fs, err := NewFlagSetFrom(cfg)
-// 		if err != nil {
-// 			t.Fatalf("Unexpected error: %q", err)
-// 		}
-// 		f, _ := os.Create(os.DevNull)
-// 		fs.SetOutput(f)
-// 		fs.ContinueOnError = true
-
-// 		fs.CmdArgs = input
-// 		if err := fs.Parse(); err == nil {
-// 			t.Errorf("Testing: FlagSet.Parse(); Expected: error with %q as args; Got: no error", input)
-// 		}
-// 	}
-// }
+func Test_Parse_NArgsRange(t *testing.T) {
+	type rangeConfig struct {
+		Pos1 []int    `flagparse:"nargs=1..2"`
+		Opt1 []string `flagparse:"name=--opt1,nargs=2..3"`
+		Opt2 []string `flagparse:"name=--opt2,nargs=0..1"`
+	}
+	good := []struct {
+		args     []string
+		expected rangeConfig
+	}{
+		{
+			args:     []string{"1"},
+			expected: rangeConfig{Pos1: []int{1}},
+		},
+		{
+			args:     []string{"1", "2", "--opt1", "a", "b"},
+			expected: rangeConfig{Pos1: []int{1, 2}, Opt1: []string{"a", "b"}},
+		},
+		{ // --opt1 stops consuming at max hence "3" goes to pos1
+			args:     []string{"--opt1", "a", "b", "c", "3", "--opt2"},
+			expected: rangeConfig{Pos1: []int{3}, Opt1: []string{"a", "b", "c"}, Opt2: []string{}},
+		},
+	}
+	for _, input := range good {
+		cfg := rangeConfig{}
+		fs, err := NewFlagSetFrom(&cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %q", err)
+		}
+		fs.SetOutput(ioutil.Discard)
+		fs.ContinueOnError = true
+		fs.CmdArgs = input.args
+		if err := fs.Parse(); err != nil {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: no error with %q as args; Got: error %q", input.args, err)
+		}
+		if !reflect.DeepEqual(cfg, input.expected) {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: %+v; Got:%+v", input.expected, cfg)
+		}
+	}
 
-func Test_Parse_HelpOption(t *testing.T) {
-	fs, _ := NewFlagSetFrom(&testConfig{})
-	fs.Desc = "flagset description"
-	fs.ContinueOnError = true
-	f, _ := os.Create(os.DevNull)
-	fs.SetOutput(f)
-	fs.CmdArgs = []string{helpLong}
-	err := fs.Parse()
-	if err == nil {
-		t.Errorf("Testing: FlagSet.Parse(); Expected: error with %q args; Got: no error", fs.CmdArgs)
+	bad := [][]string{
+		{"1", "--opt1", "a"},
+		{"1", "--opt1", "a", "--opt2"},
+		{"1", "--opt2", "a", "b"},
 	}
-	if _, ok := err.(*ErrHelpInvoked); !ok {
-		t.Errorf("Testing: FlagSet.Parse(); Expected: error of type %T with %q args; Got: error of other type", &ErrHelpInvoked{}, fs.CmdArgs)
+	for _, input := range bad {
+		fs, err := NewFlagSetFrom(&rangeConfig{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %q", err)
+		}
+		fs.SetOutput(ioutil.Discard)
+		fs.ContinueOnError = true
+		fs.CmdArgs = input
+		if err := fs.Parse(); err == nil {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: error with %q as args; Got: no error", input)
+		}
 	}
 }
 
-func Test_Parse_ExitOnError(t *testing.T) {
-	testParseExit := func() {
-		fs, _ := NewFlagSetFrom(&testConfig{})
+func Test_Parse_PosFlagWithUnlimitedArgs(t *testing.T) {
+	type testCfg struct {
+		Pos1 []int `flagparse:"nargs=-1"`
+	}
+	cfg := &testCfg{}
+
+	good := []struct {
+		args     []string
+		expected *testCfg
+	}{
+		{
+			args:     []string{"11"},
+			expected: &testCfg{Pos1: []int{11}},
+		},
+		{
+			args:     []string{"11", "22", "33", "44", "55"},
+			expected: &testCfg{Pos1: []int{11, 22, 33, 44, 55}},
+		},
+	}
+	for _, input := range good {
+		fs, err := NewFlagSetFrom(cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %q", err)
+		}
 		f, _ := os.Create(os.DevNull)
 		fs.SetOutput(f)
-		fs.CmdArgs = []string{os.Getenv("CMD_ARG")}
-		fs.Parse()
+		fs.ContinueOnError = true
+
+		fs.CmdArgs = input.args
+		if err := fs.Parse(); err != nil {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: no error with %q as args; Got: error %q", input.args, err)
+		}
+		if !reflect.DeepEqual(cfg, input.expected) {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: %+v; Got:%+v", input.expected, cfg)
+		}
 	}
-	if _, ok := os.LookupEnv("CMD_ARG"); ok {
-		testParseExit()
-		return
+
+	bad := [][]string{{}, {"11", "22", "33", "44abc", "55"}}
+	for _, input := range bad {
+		fs, err := NewFlagSetFrom(cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %q", err)
+		}
+		f, _ := os.Create(os.DevNull)
+		fs.SetOutput(f)
+		fs.ContinueOnError = true
+
+		fs.CmdArgs = input
+		if err := fs.Parse(); err == nil {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: error with %q as args; Got: no error", input)
+		}
 	}
-	data := []struct {
-		arg      string
-		expected int
+}
+
+func Test_Parse_MultipleVariadicPosFlags(t *testing.T) {
+	type cpConfig struct {
+		Src  []string `flagparse:"nargs=-1"`
+		Dest string   `flagparse:""`
+		Opt1 []string `flagparse:"name=--opt1,nargs=2"`
+	}
+	type splitConfig struct {
+		Head []int `flagparse:"nargs=1..2"`
+		Mid  []int `flagparse:"nargs=-1"`
+		Tail []int `flagparse:"nargs=2"`
+	}
+
+	good := []struct {
+		args     []string
+		cfg      interface{}
+		expected interface{}
 	}{
-		{helpLong, 1},
-		{"dummy-flag", 2},
+		{
+			args:     []string{"a", "b"},
+			cfg:      &cpConfig{},
+			expected: &cpConfig{Src: []string{"a"}, Dest: "b"},
+		},
+		{
+			args:     []string{"a", "b", "--opt1", "x", "y", "c", "d"},
+			cfg:      &cpConfig{},
+			expected: &cpConfig{Src: []string{"a", "b", "c"}, Dest: "d", Opt1: []string{"x", "y"}},
+		},
+		{
+			args:     []string{"1", "2", "3", "4"},
+			cfg:      &splitConfig{},
+			expected: &splitConfig{Head: []int{1}, Mid: []int{2}, Tail: []int{3, 4}},
+		},
+		{
+			args:     []string{"1", "2", "3", "4", "5", "6"},
+			cfg:      &splitConfig{},
+			expected: &splitConfig{Head: []int{1, 2}, Mid: []int{3, 4}, Tail: []int{5, 6}},
+		},
 	}
-	for _, input := range data {
-		cmd := exec.Command(os.Args[0], "-test.run=Test_Parse_ExitOnError")
-		cmd.Env = append(os.Environ(), "CMD_ARG="+input.arg)
-		err := cmd.Run()
-		if e, ok := err.(*exec.ExitError); ok {
-			if e.ExitCode() != input.expected {
-				t.Errorf("Testing: FlagSet.Parse(); Expected: exit code %d with args %q; Got: exit code %d", input.expected, input.arg, e.ExitCode())
-			}
+	for _, input := range good {
+		fs, err := NewFlagSetFrom(input.cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %q", err)
+		}
+		fs.SetOutput(ioutil.Discard)
+		fs.ContinueOnError = true
+		fs.CmdArgs = input.args
+		if err := fs.Parse(); err != nil {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: no error with %q as args; Got: error %q", input.args, err)
+		}
+		if !reflect.DeepEqual(input.cfg, input.expected) {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: %+v; Got:%+v", input.expected, input.cfg)
 		}
 	}
-}
 
-func Test_splitKV(t *testing.T) {
-	data := make(map[string][]string)
-	data[""] = []string{}
-	data[fmt.Sprintf("%[1]c%[1]c%[1]c", kvPairSep)] = []string{}
-	data[fmt.Sprintf("a%[1]cb%[1]cc%[1]cd", kvPairSep)] = []string{"a", "b", "c", "d"}
-	data[fmt.Sprintf("%[1]ca%[1]cb%[1]cc%[1]cd%[1]c", kvPairSep)] = []string{"a", "b", "c", "d"}
-	data[fmt.Sprintf("%[1]ca\\%[1]cb%[1]cc%[1]cd\\%[1]c", kvPairSep)] = []string{fmt.Sprintf("a%cb", kvPairSep), "c", fmt.Sprintf("d%c", kvPairSep)}
-	for input, expected := range data {
-		if got := splitKVs(input, kvPairSep); !reflect.DeepEqual(expected, got) {
-			t.Errorf("Testing: splitKV(%q, %c); Expected: %q; Got: %q", input, kvPairSep, expected, got)
+	bad := [][]string{{}, {"a"}, {"1", "2", "3"}}
+	cfgs := []interface{}{&cpConfig{}, &cpConfig{}, &splitConfig{}}
+	for i, input := range bad {
+		fs, err := NewFlagSetFrom(cfgs[i])
+		if err != nil {
+			t.Fatalf("Unexpected error: %q", err)
+		}
+		fs.SetOutput(ioutil.Discard)
+		fs.ContinueOnError = true
+		fs.CmdArgs = input
+		if err := fs.Parse(); err == nil {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: error with %q as args; Got: no error", input)
 		}
 	}
 }
 
-func Test_parseKVs_InvalidKeyValues(t *testing.T) {
-	invalidKVs := []string{
-		"hello",
-		"usage=",
-		"hello=hi",
-		"name=flag_name",
-		"name=flag-name:",
-		"name=:flag-name",
-		"nargs=1x",
+func Test_Parse_OptionalPosFlags(t *testing.T) {
+	type buildConfig struct {
+		Cmd    string   `flagparse:""`
+		Target string   `flagparse:"optional=true"`
+		Extra  []string `flagparse:"nargs=-1,optional=true"`
+	}
+	data := []struct {
+		args     []string
+		expected buildConfig
+	}{
+		{
+			args:     []string{"build"},
+			expected: buildConfig{Cmd: "build", Target: "all", Extra: []string{"x"}},
+		},
+		{
+			args:     []string{"build", "tests"},
+			expected: buildConfig{Cmd: "build", Target: "tests", Extra: []string{"x"}},
+		},
+		{
+			args:     []string{"build", "tests", "a", "b"},
+			expected: buildConfig{Cmd: "build", Target: "tests", Extra: []string{"a", "b"}},
+		},
+	}
+	for _, input := range data {
+		cfg := buildConfig{Target: "all", Extra: []string{"x"}}
+		fs, err := NewFlagSetFrom(&cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %q", err)
+		}
+		fs.SetOutput(ioutil.Discard)
+		fs.ContinueOnError = true
+		fs.CmdArgs = input.args
+		if err := fs.Parse(); err != nil {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: no error with %q as args; Got: error %q", input.args, err)
+		}
+		if !reflect.DeepEqual(cfg, input.expected) {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: %+v; Got:%+v", input.expected, cfg)
+		}
 	}
 
-	for _, kv := range invalidKVs {
-		if _, err := parseKVs(kv); err == nil {
-			t.Errorf("Testing: parseTags(%q); Expected: error; Got: no error", kv)
+	fs, _ := NewFlagSetFrom(&buildConfig{})
+	fs.name = "tool"
+	expected := "tool [-h] cmd [target] [extra...]"
+	if got := fs.synopsis(); got != expected {
+		t.Errorf("Testing: FlagSet.synopsis(); Expected: %q; Got: %q", expected, got)
+	}
+
+	// an optional positional flag may be omitted but when given it needs all of its arguments
+	invalid := []struct {
+		src      interface{}
+		args     []string
+		expected ArgCountError
+	}{
+		{
+			src: &struct {
+				Pair []int `flagparse:"nargs=2,optional=true"`
+			}{},
+			args:     []string{"1"},
+			expected: ArgCountError{Flag: "pair", Pos: 0, Min: 2, Max: 2, Given: 1},
+		},
+		{
+			src: &struct {
+				Aa []int `flagparse:"nargs=2..3,optional=true"`
+				Bb int   `flagparse:""`
+			}{},
+			args:     []string{"1", "2"},
+			expected: ArgCountError{Flag: "aa", Pos: 0, Min: 2, Max: 3, Given: 1},
+		},
+	}
+	for _, input := range invalid {
+		fs, err := NewFlagSetFrom(input.src)
+		if err != nil {
+			t.Fatalf("Unexpected error: %q", err)
+		}
+		fs.SetOutput(ioutil.Discard)
+		fs.ContinueOnError = true
+		var target *ArgCountError
+		if err := fs.ParseArgs(input.args); !errors.As(err, &target) || *target != input.expected {
+			t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: %+v; Got: %v", input.args, input.expected, err)
 		}
 	}
 }
 
-func Test_parseKVs_ValidKeyValues(t *testing.T) {
+func Test_Parse_StopAtFirstPositional(t *testing.T) {
+	type wrapperConfig struct {
+		Verbose bool     `flagparse:"name=-v,nargs=0"`
+		Cmd     string   `flagparse:""`
+		Args    []string `flagparse:"nargs=-1,optional=true"`
+	}
 	data := []struct {
-		kvs      string
-		expected map[string]string
+		stop     bool
+		args     []string
+		expected wrapperConfig
 	}{
 		{
-			"",
-			map[string]string{},
+			stop:     true,
+			args:     []string{"-v", "ls", "-l", "-v", "dir"},
+			expected: wrapperConfig{Verbose: true, Cmd: "ls", Args: []string{"-l", "-v", "dir"}},
 		},
 		{
-			"name=flag-name123,usage=hello\\,world,nargs=10",
-			map[string]string{
-				nargsKey: "10",
-				nameKey:  "flag-name123",
-				usageKey: "hello,world",
-			},
+			stop:     true,
+			args:     []string{"ls"},
+			expected: wrapperConfig{Cmd: "ls"},
 		},
 		{
-			"name=-f123:--Flag-Name123,usage=abc,nargs=-10",
-			map[string]string{
-				nargsKey: "-10",
-				nameKey:  "-f123:--Flag-Name123",
-				usageKey: "abc",
-			},
+			stop:     false,
+			args:     []string{"ls", "dir", "-v"},
+			expected: wrapperConfig{Verbose: true, Cmd: "ls", Args: []string{"dir"}},
 		},
 	}
+	for _, input := range data {
+		cfg := wrapperConfig{}
+		fs, err := NewFlagSetFrom(&cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %q", err)
+		}
+		fs.SetOutput(ioutil.Discard)
+		fs.ContinueOnError = true
+		fs.StopAtFirstPositional = input.stop
+		fs.CmdArgs = input.args
+		if err := fs.Parse(); err != nil {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: no error with %q as args; Got: error %q", input.args, err)
+		}
+		if !reflect.DeepEqual(cfg, input.expected) {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: %+v; Got:%+v", input.expected, cfg)
+		}
+	}
+}
 
+func Test_ParseKnown(t *testing.T) {
+	type knownConfig struct {
+		Pos1 int      `flagparse:""`
+		Opt1 []string `flagparse:"name=--opt1,nargs=-1"`
+		Sw1  bool     `flagparse:"name=-s,nargs=0"`
+	}
+	data := []struct {
+		args            []string
+		expected        knownConfig
+		expectedUnknown []string
+	}{
+		{
+			args:     []string{"10"},
+			expected: knownConfig{Pos1: 10},
+		},
+		{
+			args:            []string{"--dummy", "10", "-s", "extra", "--opt1", "a", "b", "--other"},
+			expected:        knownConfig{Pos1: 10, Opt1: []string{"a", "b"}, Sw1: true},
+			expectedUnknown: []string{"--dummy", "extra", "--other"},
+		},
+		{ // an unknown flag closes the current flag, its arguments go to positional flags
+			args:            []string{"--opt1", "a", "--dummy", "10", "x", "y"},
+			expected:        knownConfig{Pos1: 10, Opt1: []string{"a"}},
+			expectedUnknown: []string{"--dummy", "x", "y"},
+		},
+	}
 	for _, input := range data {
-		got, err := parseKVs(input.kvs)
+		cfg := knownConfig{}
+		fs, err := NewFlagSetFrom(&cfg)
 		if err != nil {
-			t.Errorf("Testing: parseTags(%q); Expected: no error; Got: %s", input.kvs, err)
+			t.Fatalf("Unexpected error: %q", err)
+		}
+		fs.SetOutput(ioutil.Discard)
+		fs.ContinueOnError = true
+		fs.CmdArgs = input.args
+		unknown, err := fs.ParseKnown()
+		if err != nil {
+			t.Errorf("Testing: FlagSet.ParseKnown(); Expected: no error with %q as args; Got: error %q", input.args, err)
+		}
+		if !reflect.DeepEqual(cfg, input.expected) {
+			t.Errorf("Testing: FlagSet.ParseKnown(); Expected: %+v; Got:%+v", input.expected, cfg)
+		}
+		if len(unknown) != len(input.expectedUnknown) || (len(unknown) > 0 && !reflect.DeepEqual(unknown, input.expectedUnknown)) {
+			t.Errorf("Testing: FlagSet.ParseKnown(); Expected: unknown %q; Got: %q", input.expectedUnknown, unknown)
 		}
+	}
 
-		if !reflect.DeepEqual(input.expected, got) {
-			t.Errorf("Testing: parseTags(%q); Expected: %+v; Got: %+v", input.kvs, input.expected, got)
+	// errors other than unknown flags/arguments are still reported
+	fs, _ := NewFlagSetFrom(&knownConfig{})
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+	fs.CmdArgs = []string{"--dummy", "abc"}
+	if _, err := fs.ParseKnown(); err == nil {
+		t.Errorf("Testing: FlagSet.ParseKnown(); Expected: error with %q as args; Got: no error", fs.CmdArgs)
+	}
+}
+
+func Test_Parse_AllowAbbrev(t *testing.T) {
+	type abbrevConfig struct {
+		Verbose bool   `flagparse:"name=--verbose:--loud,nargs=0"`
+		Version bool   `flagparse:"name=--version,nargs=0"`
+		Output  string `flagparse:"name=--output:--out"`
+	}
+	good := []struct {
+		args     []string
+		expected abbrevConfig
+	}{
+		{
+			args:     []string{"--verb", "--vers"},
+			expected: abbrevConfig{Verbose: true, Version: true},
+		},
+		{ // exact match wins even when it is a prefix of another name
+			args:     []string{"--out", "file"},
+			expected: abbrevConfig{Output: "file"},
+		},
+		{ // all matching names are aliases of the same flag
+			args:     []string{"--ou", "file", "--l"},
+			expected: abbrevConfig{Verbose: true, Output: "file"},
+		},
+	}
+	for _, input := range good {
+		cfg := abbrevConfig{}
+		fs, err := NewFlagSetFrom(&cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %q", err)
 		}
+		fs.SetOutput(ioutil.Discard)
+		fs.ContinueOnError = true
+		fs.AllowAbbrev = true
+		fs.CmdArgs = input.args
+		if err := fs.Parse(); err != nil {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: no error with %q as args; Got: error %q", input.args, err)
+		}
+		if !reflect.DeepEqual(cfg, input.expected) {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: %+v; Got:%+v", input.expected, cfg)
+		}
+	}
+
+	bad := []struct {
+		abbrev bool
+		args   []string
+	}{
+		{true, []string{"--ver"}},
+		{true, []string{"--"}},
+		{false, []string{"--verb"}},
+	}
+	for _, input := range bad {
+		fs, _ := NewFlagSetFrom(&abbrevConfig{})
+		fs.SetOutput(ioutil.Discard)
+		fs.ContinueOnError = true
+		fs.AllowAbbrev = input.abbrev
+		fs.CmdArgs = input.args
+		if err := fs.Parse(); err == nil {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: error with %q as args; Got: no error", input.args)
+		}
+	}
+
+	fs, _ := NewFlagSetFrom(&abbrevConfig{})
+	fs.AllowAbbrev = true
+	expected := "ambiguous option --ver could match --verbose, --version"
+	if _, err := fs.lookupOptName("--ver", 0); err == nil || err.Error() != expected {
+		t.Errorf("Testing: FlagSet.lookupOptName(\"--ver\"); Expected: error %q; Got: %v", expected, err)
+	}
+}
+
+func Test_editDistance(t *testing.T) {
+	data := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "abc", 0},
+		{"--ouptut", "--output", 2},
+		{"kitten", "sitting", 3},
+	}
+	for _, input := range data {
+		if got := editDistance(input.a, input.b); got != input.expected {
+			t.Errorf("Testing: editDistance(%q, %q); Expected: %d; Got: %d", input.a, input.b, input.expected, got)
+		}
+	}
+}
+
+func Test_Parse_Suggestions(t *testing.T) {
+	type suggestConfig struct {
+		Output string `flagparse:"name=--output:-o"`
+		Outdir string `flagparse:"name=--outdir"`
+		Input  string `flagparse:"name=--input"`
+		Host   string `flagparse:"name=--host"`
+		Port   string `flagparse:"name=--port"`
+		Num    int    `flagparse:"name=-n"`
+		Silent bool   `flagparse:"name=-s,nargs=0"`
+	}
+	data := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--ouptut", "x"}, "unrecognized flag --ouptut, did you mean --output?"},
+		{[]string{"--post", "x"}, "unrecognized flag --post, did you mean --host or --port?"},
+		{[]string{"--completely-different"}, "unrecognized flag --completely-different"},
+		{[]string{"input"}, "unrecognized argument: input, did you mean --input?"},
+		// short names are too close to every other short name for a suggestion to be useful
+		{[]string{"-x"}, "unrecognized flag -x"},
+		{[]string{"-n", "-1"}, "unrecognized flag -1"},
+		{[]string{"x"}, "unrecognized argument: x"},
+		{[]string{"os"}, "unrecognized argument: os"},
+	}
+	for _, input := range data {
+		fs, _ := NewFlagSetFrom(&suggestConfig{})
+		fs.SetOutput(ioutil.Discard)
+		fs.ContinueOnError = true
+		fs.CmdArgs = input.args
+		if err := fs.Parse(); err == nil || err.Error() != input.expected {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: error %q with %q as args; Got: %v", input.expected, input.args, err)
+		}
+	}
+}
+
+func Test_ParseArgs_Repeated(t *testing.T) {
+	cfg := &testConfig{
+		Opt1: "hello",
+		Opt2: []int{11},
+	}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+
+	// a parse stopped in between must not affect the next one
+	if err := fs.ParseArgs([]string{"10", "--opt3", "one", helpLong}); err == nil {
+		t.Errorf("Testing: FlagSet.ParseArgs(); Expected: error; Got: no error")
+	}
+	data := []struct {
+		args     []string
+		expected *testConfig
+	}{
+		{
+			args: []string{"10", "1.1", "2.2", "--opt2", "22", "33", "-s"},
+			expected: &testConfig{Pos1: 10, Pos2: []float64{1.1, 2.2}, Opt1: "hello", Opt2: []int{22, 33},
+				Sw1: true},
+		},
+		{
+			args:     []string{"20", "3.3", "4.4", "--opt1", "world"},
+			expected: &testConfig{Pos1: 20, Pos2: []float64{3.3, 4.4}, Opt1: "world", Opt2: []int{11}},
+		},
+	}
+	for _, input := range data {
+		fs.Reset()
+		if err := fs.ParseArgs(input.args); err != nil {
+			t.Errorf("Testing: FlagSet.ParseArgs(); Expected: no error with %q as args; Got: error %q", input.args, err)
+		}
+		if !reflect.DeepEqual(cfg, input.expected) {
+			t.Errorf("Testing: FlagSet.ParseArgs(); Expected: %+v; Got:%+v", input.expected, cfg)
+		}
+	}
+}
+
+func Test_Reset(t *testing.T) {
+	cfg := &testConfig{Pos1: 5, Opt2: []int{1, 2}}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	cfg.Pos1 = 6
+	cfg.Opt2[0] = 100
+	cfg.Opt1 = "changed"
+	cfg.Sw1 = true
+	fs.Reset()
+	expected := &testConfig{Pos1: 5, Opt2: []int{1, 2}}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Testing: FlagSet.Reset(); Expected: %+v; Got:%+v", expected, cfg)
+	}
+}
+
+func Test_Reset_TextTypes(t *testing.T) {
+	type textConfig struct {
+		N     big.Int   `flagparse:"name=--n"`
+		Level testLevel `flagparse:"name=--level"`
+	}
+	cfg := &textConfig{Level: 1}
+	cfg.N.SetInt64(12345678901234)
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+
+	// big.Int reuses its storage when set, so the default must survive more than one round
+	for i := 0; i < 2; i++ {
+		args := []string{"--n", "99", "--level", "error"}
+		if err := fs.ParseArgs(args); err != nil {
+			t.Fatalf("Testing: FlagSet.ParseArgs(%q); Expected: no error; Got: %q", args, err)
+		}
+		if cfg.N.Int64() != 99 || cfg.Level != 2 {
+			t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: n=99, level=error; Got: %+v", args, cfg)
+		}
+		fs.Reset()
+		if cfg.N.Int64() != 12345678901234 || cfg.Level != 1 {
+			t.Errorf("Testing: FlagSet.Reset(); Expected: n=12345678901234, level=info; Got: n=%s, level=%v",
+				&cfg.N, cfg.Level)
+		}
+	}
+}
+
+func Test_ParseInto(t *testing.T) {
+	shared := &testConfig{Opt1: "hello"}
+	fs, err := NewFlagSetFrom(shared)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dst := &testConfig{Opt1: "default"}
+			args := []string{strconv.Itoa(i), "1.5", "2.5", "--opt2", strconv.Itoa(i * 2)}
+			expected := &testConfig{Pos1: i, Pos2: []float64{1.5, 2.5}, Opt1: "default", Opt2: []int{i * 2}}
+			if i%2 == 0 {
+				args = append(args, "-s", "-t", "even")
+				expected.Sw1 = true
+				expected.Opt1 = "even"
+			}
+			if err := fs.ParseInto(dst, args); err != nil {
+				t.Errorf("Testing: FlagSet.ParseInto(); Expected: no error with %q as args; Got: error %q", args, err)
+			}
+			if !reflect.DeepEqual(dst, expected) {
+				t.Errorf("Testing: FlagSet.ParseInto(); Expected: %+v; Got:%+v", expected, dst)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if !reflect.DeepEqual(shared, &testConfig{Opt1: "hello"}) {
+		t.Errorf("Testing: FlagSet.ParseInto(); Expected: original struct to be untouched; Got: %+v", shared)
+	}
+
+	invalid := []interface{}{nil, testConfig{}, &struct{ Pos1 int }{}}
+	for _, dst := range invalid {
+		if err := fs.ParseInto(dst, []string{"1", "2", "3"}); err == nil {
+			t.Errorf("Testing: FlagSet.ParseInto(%#v); Expected: error; Got: no error", dst)
+		}
+	}
+	if err := NewFlagSet().ParseInto(&testConfig{}, nil); err == nil {
+		t.Errorf("Testing: FlagSet.ParseInto() on FlagSet not created from struct; Expected: error; Got: no error")
+	}
+}
+
+func Test_Parse_TimeTypes(t *testing.T) {
+	type timeConfig struct {
+		Since   time.Time       `flagparse:"name=--since,layout=2006-01-02"`
+		Until   time.Time       `flagparse:"name=--until"`
+		Timeout time.Duration   `flagparse:"name=--timeout"`
+		Retries []time.Duration `flagparse:"name=--retries,nargs=-1"`
+	}
+	cfg := &timeConfig{Timeout: 5 * time.Second}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+
+	if def := fs.Lookup("--timeout").DefValue(); def != "5s" {
+		t.Errorf("Testing: default of --timeout; Expected: %q; Got: %q", "5s", def)
+	}
+	if def := fs.Lookup("--since").DefValue(); def != "" {
+		t.Errorf("Testing: default of --since; Expected: empty string for zero time; Got: %q", def)
+	}
+
+	args := []string{"--since", "2020-02-01", "--until", "2020-03-01T10:00:00Z", "--timeout", "1m30s",
+		"--retries", "1s", "2s"}
+	if err := fs.ParseArgs(args); err != nil {
+		t.Fatalf("Testing: FlagSet.ParseArgs(%q); Expected: no error; Got: %q", args, err)
+	}
+	expected := &timeConfig{
+		Since:   time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+		Until:   time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC),
+		Timeout: 90 * time.Second,
+		Retries: []time.Duration{time.Second, 2 * time.Second},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: %+v; Got: %+v", args, expected, cfg)
+	}
+
+	// values created for ParseInto must honour the layout too
+	dst := &timeConfig{}
+	if err := fs.ParseInto(dst, []string{"--since", "2021-05-06"}); err != nil {
+		t.Fatalf("Testing: FlagSet.ParseInto(); Expected: no error; Got: %q", err)
+	}
+	if !dst.Since.Equal(time.Date(2021, 5, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Testing: FlagSet.ParseInto(); Expected: --since to be parsed with layout; Got: %v", dst.Since)
+	}
+
+	for _, args := range [][]string{{"--since", "2020-02-01T00:00:00Z"}, {"--timeout", "10"}} {
+		if err := fs.ParseArgs(args); err == nil {
+			t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: error; Got: no error", args)
+		}
+	}
+
+	fs.Reset()
+	if !cfg.Since.IsZero() || cfg.Timeout != 5*time.Second || cfg.Retries != nil {
+		t.Errorf("Testing: FlagSet.Reset(); Expected: default values; Got: %+v", cfg)
+	}
+}
+
+func Test_Parse_AdaptedTypes(t *testing.T) {
+	type adaptedConfig struct {
+		Level testLevel   `flagparse:"name=--level"`
+		Tags  testStdList `flagparse:"name=--tags,nargs=-1"`
+		Num   big.Int     `flagparse:"name=--num"`
+	}
+	cfg := &adaptedConfig{Level: 1}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+
+	if def := fs.Lookup("--level").DefValue(); def != "info" {
+		t.Errorf("Testing: default of --level; Expected: %q; Got: %q", "info", def)
+	}
+	args := []string{"--level", "error", "--tags", "x", "y", "--num", "0x10"}
+	if err := fs.ParseArgs(args); err != nil {
+		t.Fatalf("Testing: FlagSet.ParseArgs(%q); Expected: no error; Got: %q", args, err)
+	}
+	if cfg.Level != 2 || !reflect.DeepEqual(cfg.Tags, testStdList{"x", "y"}) || cfg.Num.Int64() != 16 {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: all fields set; Got: %+v", args, cfg)
+	}
+	if err := fs.ParseArgs([]string{"--level", "fatal"}); err == nil {
+		t.Errorf("Testing: FlagSet.ParseArgs() with invalid level; Expected: error; Got: no error")
+	}
+
+	fs.Reset()
+	if cfg.Level != 1 || cfg.Tags != nil {
+		t.Errorf("Testing: FlagSet.Reset(); Expected: default values; Got: %+v", cfg)
+	}
+}
+
+func Test_Parse_SliceAndArrayTypes(t *testing.T) {
+	type sliceConfig struct {
+		Point  [3]float64   `flagparse:""`
+		Levels []testLevel  `flagparse:"name=--levels,nargs=-1"`
+		Pair   [2]int8      `flagparse:"name=--pair"`
+		Dates  [1]time.Time `flagparse:"name=--dates,layout=2006-01-02"`
+	}
+	cfg := &sliceConfig{Pair: [2]int8{1, 2}}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+
+	for name, nargs := range map[string]int{"point": 3, "--levels": -1, "--pair": 2, "--dates": 1} {
+		if got := fs.Lookup(name).NArgs(); got != nargs {
+			t.Errorf("Testing: nargs of %s; Expected: %d; Got: %d", name, nargs, got)
+		}
+	}
+	if def := fs.Lookup("--pair").DefValue(); def != "[1 2]" {
+		t.Errorf("Testing: default of --pair; Expected: %q; Got: %q", "[1 2]", def)
+	}
+
+	args := []string{"1", "2", "3", "--levels", "info", "error", "--pair", "0", "127", "--dates", "2020-01-02"}
+	if err := fs.ParseArgs(args); err != nil {
+		t.Fatalf("Testing: FlagSet.ParseArgs(%q); Expected: no error; Got: %q", args, err)
+	}
+	expected := &sliceConfig{
+		Point:  [3]float64{1, 2, 3},
+		Levels: []testLevel{1, 2},
+		Pair:   [2]int8{0, 127},
+		Dates:  [1]time.Time{time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: %+v; Got: %+v", args, expected, cfg)
+	}
+
+	for _, args := range [][]string{{"1", "2"}, {"1", "2", "3", "--pair", "1", "128"}} {
+		if err := fs.ParseArgs(args); err == nil {
+			t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: error; Got: no error", args)
+		}
+	}
+
+	fs.Reset()
+	if cfg.Pair != [2]int8{1, 2} || cfg.Levels != nil {
+		t.Errorf("Testing: FlagSet.Reset(); Expected: default values; Got: %+v", cfg)
+	}
+}
+
+func Test_Parse_MapTypes(t *testing.T) {
+	type mapConfig struct {
+		Labels   map[string]string        `flagparse:"name=--label:-l"`
+		Timeouts map[string]time.Duration `flagparse:"name=--timeout,nargs=-1,dupkeys=error"`
+	}
+	cfg := &mapConfig{Labels: map[string]string{"env": "dev"}}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+
+	if def := fs.Lookup("--label").DefValue(); def != "[env=dev]" {
+		t.Errorf("Testing: default of --label; Expected: %q; Got: %q", "[env=dev]", def)
+	}
+
+	// pairs of all occurrences are collected
+	// values are split at the first "=" only and kept as is
+	args := []string{"--label", "a=1", "-l", "b=x=y", "--label", "a=2", "-l", "k=", "-l", "p=C:\\dir",
+		"--timeout", "read=1s", "write=2s"}
+	if err := fs.ParseArgs(args); err != nil {
+		t.Fatalf("Testing: FlagSet.ParseArgs(%q); Expected: no error; Got: %q", args, err)
+	}
+	expected := &mapConfig{
+		Labels:   map[string]string{"a": "2", "b": "x=y", "k": "", "p": "C:\\dir"},
+		Timeouts: map[string]time.Duration{"read": time.Second, "write": 2 * time.Second},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: %+v; Got: %+v", args, expected, cfg)
+	}
+
+	// a new parse starts with a fresh map
+	if err := fs.ParseArgs([]string{"--label", "c=3"}); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	if !reflect.DeepEqual(cfg.Labels, map[string]string{"c": "3"}) {
+		t.Errorf("Testing: FlagSet.ParseArgs() again; Expected: %v; Got: %v", map[string]string{"c": "3"}, cfg.Labels)
+	}
+
+	invalid := [][]string{
+		{"--label", "novalue"},
+		{"--timeout", "read=1s", "read=2s"},
+		{"--timeout", "read=1s", "--timeout", "read=2s"},
+		{"--timeout", "read=1"},
+	}
+	for _, args := range invalid {
+		if err := fs.ParseArgs(args); err == nil {
+			t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: error; Got: no error", args)
+		}
+		// duplicates must also be rejected when parsing into another destination
+		if err := fs.ParseInto(&mapConfig{}, args); err == nil {
+			t.Errorf("Testing: FlagSet.ParseInto(%q); Expected: error; Got: no error", args)
+		}
+	}
+
+	fs.Reset()
+	if !reflect.DeepEqual(cfg.Labels, map[string]string{"env": "dev"}) || cfg.Timeouts != nil {
+		t.Errorf("Testing: FlagSet.Reset(); Expected: default values; Got: %+v", cfg)
+	}
+
+	if c := fs.Lookup("--timeout").schema().Constraints; !reflect.DeepEqual(c, map[string]string{dupKeysKey: "error"}) {
+		t.Errorf("Testing: FlagSchema.Constraints of --timeout; Expected: dupkeys constraint; Got: %v", c)
+	}
+}
+
+func Test_Parse_Separator(t *testing.T) {
+	type sepConfig struct {
+		Hosts  []string          `flagparse:"name=--hosts,sep=\\,"`
+		Ports  []int             `flagparse:"name=--ports,sep=:,nargs=-1"`
+		Point  [3]float64        `flagparse:"name=--point,sep=\\,"`
+		Labels map[string]string `flagparse:"name=--label,sep=;"`
+		Files  []string          `flagparse:"sep=\\,"`
+	}
+	cfg := &sepConfig{}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+
+	if nargs := fs.Lookup("--point").NArgs(); nargs != 1 {
+		t.Errorf("Testing: nargs of --point; Expected: 1; Got: %d", nargs)
+	}
+
+	args := []string{"--hosts", "a,b\\,c", "--ports", "80:443", "8080", "--hosts", "d", "--point", "1,2,3",
+		"--label", "x=1;y=2", "f1,f2"}
+	if err := fs.ParseArgs(args); err != nil {
+		t.Fatalf("Testing: FlagSet.ParseArgs(%q); Expected: no error; Got: %q", args, err)
+	}
+	expected := &sepConfig{
+		Hosts:  []string{"a", "b,c", "d"},
+		Ports:  []int{80, 443, 8080},
+		Point:  [3]float64{1, 2, 3},
+		Labels: map[string]string{"x": "1", "y": "2"},
+		Files:  []string{"f1", "f2"},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: %+v; Got: %+v", args, expected, cfg)
+	}
+
+	// elements are collected per parse only
+	dst := &sepConfig{}
+	if err := fs.ParseInto(dst, []string{"--hosts", "e,f", "g"}); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	if !reflect.DeepEqual(dst.Hosts, []string{"e", "f"}) || !reflect.DeepEqual(dst.Files, []string{"g"}) {
+		t.Errorf("Testing: FlagSet.ParseInto(); Expected: fresh elements; Got: %+v", dst)
+	}
+
+	for _, args := range [][]string{{"--point", "1,2", "f"}, {"--ports", "80:x", "f"}} {
+		if err := fs.ParseArgs(args); err == nil {
+			t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: error; Got: no error", args)
+		}
+	}
+
+	// only backslashes escaping the separator are removed and empty elements are kept
+	args = []string{"--hosts", `C:\dir,D:\x`, "--hosts", "a,,b", "--label", `k\=x=1;y=`, "f"}
+	if err := fs.ParseArgs(args); err != nil {
+		t.Fatalf("Testing: FlagSet.ParseArgs(%q); Expected: no error; Got: %q", args, err)
+	}
+	if !reflect.DeepEqual(cfg.Hosts, []string{`C:\dir`, `D:\x`, "a", "", "b"}) ||
+		!reflect.DeepEqual(cfg.Labels, map[string]string{"k=x": "1", "y": ""}) {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: backslashes kept; Got: %+v", args, cfg)
+	}
+
+	// the elements of all occurrences of an array flag fill the array together
+	args = []string{"--point", "1,2", "--point", "3", "f"}
+	if err := fs.ParseArgs(args); err != nil || cfg.Point != [3]float64{1, 2, 3} {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: point [1 2 3]; Got: %v, error %v", args, cfg.Point, err)
+	}
+	args = []string{"--point", "1,2,3", "--point", "4,5,6", "f"}
+	var parseErr *ValueParseError
+	if err := fs.ParseArgs(args); !errors.As(err, &parseErr) || parseErr.Flag != "--point" || parseErr.Pos != 0 ||
+		len(parseErr.Args) != 6 {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: %T for 6 elements of --point; Got: %v", args, parseErr, err)
+	}
+
+	// the separator survives a round trip through the schema
+	fs2, err := NewFlagSetFromSchema(fs.Schema(), make(map[string]interface{}))
+	if err == nil {
+		t.Errorf("Testing: NewFlagSetFromSchema() with array type; Expected: error; Got: %v", fs2)
+	}
+	s := &Schema{Optionals: []*FlagSchema{fs.Lookup("--hosts").schema()}}
+	if c := s.Optionals[0].Constraints; !reflect.DeepEqual(c, map[string]string{sepKey: ","}) {
+		t.Errorf("Testing: FlagSchema.Constraints of --hosts; Expected: sep constraint; Got: %v", c)
+	}
+	fs2, err = NewFlagSetFromSchema(s, make(map[string]interface{}))
+	if err != nil {
+		t.Fatalf("Testing: NewFlagSetFromSchema(); Expected: no error; Got: %q", err)
+	}
+	if sep := fs2.Lookup("--hosts").Separator(); sep != ',' {
+		t.Errorf("Testing: Separator() after round trip; Expected: ','; Got: %q", sep)
+	}
+}
+
+func Test_Parse_PointerTypes(t *testing.T) {
+	type ptrConfig struct {
+		Count   *int           `flagparse:"name=--count"`
+		Name    *string        `flagparse:"name=--name"`
+		Verbose *bool          `flagparse:"name=-v,nargs=0"`
+		Timeout *time.Duration `flagparse:"name=--timeout"`
+		Hosts   *[]string      `flagparse:"name=--hosts,sep=\\,"`
+	}
+	defName := "default"
+	cfg := &ptrConfig{Name: &defName}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+
+	for name, def := range map[string]string{"--count": "none", "--name": "default", "--timeout": "none"} {
+		if got := fs.Lookup(name).DefValue(); got != def {
+			t.Errorf("Testing: default of %s; Expected: %q; Got: %q", name, def, got)
+		}
+	}
+
+	// flags not given stay nil
+	if err := fs.ParseArgs([]string{"--count", "0"}); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	if cfg.Count == nil || *cfg.Count != 0 || cfg.Verbose != nil || cfg.Timeout != nil || cfg.Hosts != nil {
+		t.Errorf("Testing: FlagSet.ParseArgs(); Expected: only Count set; Got: %+v", cfg)
+	}
+	if *cfg.Name != "default" {
+		t.Errorf("Testing: FlagSet.ParseArgs(); Expected: Name to keep its default; Got: %q", *cfg.Name)
+	}
+
+	args := []string{"--name", "x", "-v", "--timeout", "1s", "--hosts", "a,b"}
+	if err := fs.ParseArgs(args); err != nil {
+		t.Fatalf("Testing: FlagSet.ParseArgs(%q); Expected: no error; Got: %q", args, err)
+	}
+	if *cfg.Name != "x" || !*cfg.Verbose || *cfg.Timeout != time.Second || !reflect.DeepEqual(*cfg.Hosts, []string{"a", "b"}) {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: all fields set; Got: %+v", args, cfg)
+	}
+	if defName != "default" {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: default variable to be untouched; Got: %q", args, defName)
+	}
+
+	dst := &ptrConfig{}
+	if err := fs.ParseInto(dst, []string{"--count", "5"}); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	if dst.Count == nil || *dst.Count != 5 || dst.Name != nil {
+		t.Errorf("Testing: FlagSet.ParseInto(); Expected: only Count set; Got: %+v", dst)
+	}
+
+	fs.Reset()
+	if cfg.Count != nil || cfg.Name != &defName || cfg.Verbose != nil {
+		t.Errorf("Testing: FlagSet.Reset(); Expected: default values; Got: %+v", cfg)
+	}
+}
+
+func Test_Parse_PointerTypes_OriginalUntouched(t *testing.T) {
+	type ptrConfig struct {
+		B *big.Int `flagparse:"name=-b"`
+	}
+	orig := big.NewInt(7)
+	cfg := &ptrConfig{B: orig}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+	if def := fs.Lookup("-b").DefValue(); def != "7" {
+		t.Errorf("Testing: default of -b; Expected: %q; Got: %q", "7", def)
+	}
+
+	// every Set allocates a new variable and the one given by the caller is never modified
+	var got []*big.Int
+	for _, arg := range []string{"99", "5"} {
+		if err := fs.ParseArgs([]string{"-b", arg}); err != nil {
+			t.Fatalf("Unexpected error: %q", err)
+		}
+		if cfg.B.String() != arg || cfg.B == orig {
+			t.Errorf("Testing: FlagSet.ParseArgs(-b %s); Expected: new variable set to %s; Got: %v", arg, arg, cfg.B)
+		}
+		got = append(got, cfg.B)
+	}
+	if got[0] == got[1] || got[0].String() != "99" || orig.Int64() != 7 {
+		t.Errorf("Testing: FlagSet.ParseArgs(); Expected: earlier values untouched; Got: %v and original %v", got, orig)
+	}
+
+	fs.Reset()
+	if cfg.B != orig || orig.Int64() != 7 {
+		t.Errorf("Testing: FlagSet.Reset(); Expected: original pointer to 7; Got: %v", cfg.B)
+	}
+}
+
+func Test_Parse_NetworkTypes(t *testing.T) {
+	type netConfig struct {
+		Bind      net.IP      `flagparse:"name=--bind"`
+		Allow     []net.IPNet `flagparse:"name=--allow,nargs=-1"`
+		Peers     []HostPort  `flagparse:"name=--peers,nargs=-1,defport=7946"`
+		Upstream  *url.URL    `flagparse:"name=--upstream,schemes=http:https"`
+		Endpoints []*url.URL  `flagparse:"name=--endpoints,sep=\\,,schemes=grpc"`
+	}
+	cfg := &netConfig{Bind: net.IPv4(127, 0, 0, 1)}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+
+	for name, def := range map[string]string{"--bind": "127.0.0.1", "--allow": "[]", "--upstream": "none"} {
+		if got := fs.Lookup(name).DefValue(); got != def {
+			t.Errorf("Testing: default of %s; Expected: %q; Got: %q", name, def, got)
+		}
+	}
+
+	args := []string{"--bind", "::", "--allow", "10.0.0.0/8", "fd00::/8", "--peers", "a", "b:1",
+		"--upstream", "https://up", "--endpoints", "grpc://x,grpc://y"}
+	if err := fs.ParseArgs(args); err != nil {
+		t.Fatalf("Testing: FlagSet.ParseArgs(%q); Expected: no error; Got: %q", args, err)
+	}
+	if !cfg.Bind.Equal(net.IPv6zero) || len(cfg.Allow) != 2 || cfg.Allow[1].String() != "fd00::/8" {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: --bind and --allow set; Got: %+v", args, cfg)
+	}
+	if !reflect.DeepEqual(cfg.Peers, []HostPort{{"a", "7946"}, {"b", "1"}}) {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: --peers with default port; Got: %v", args, cfg.Peers)
+	}
+	if cfg.Upstream == nil || cfg.Upstream.String() != "https://up" || len(cfg.Endpoints) != 2 ||
+		cfg.Endpoints[1].Host != "y" {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: URLs set; Got: %v, %v", args, cfg.Upstream, cfg.Endpoints)
+	}
+
+	invalid := [][]string{
+		{"--bind", "localhost"},
+		{"--allow", "10.0.0.1"},
+		{"--upstream", "ftp://up"},
+		{"--endpoints", "grpc://x,http://y"},
+	}
+	for _, args := range invalid {
+		if err := fs.ParseArgs(args); err == nil {
+			t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: error; Got: no error", args)
+		}
+	}
+
+	// tag keys must be part of the schema and survive a round trip
+	expected := map[string]map[string]string{
+		"--peers":     {defPortKey: "7946"},
+		"--upstream":  {schemesKey: "http:https"},
+		"--endpoints": {schemesKey: "grpc", sepKey: ","},
+	}
+	for name, c := range expected {
+		if got := fs.Lookup(name).schema().Constraints; !reflect.DeepEqual(got, c) {
+			t.Errorf("Testing: FlagSchema.Constraints of %s; Expected: %v; Got: %v", name, c, got)
+		}
+	}
+	dst := make(map[string]interface{})
+	fs2, err := NewFlagSetFromSchema(fs.Schema(), dst)
+	if err != nil {
+		t.Fatalf("Testing: NewFlagSetFromSchema(); Expected: no error; Got: %q", err)
+	}
+	fs2.ContinueOnError = true
+	fs2.SetOutput(ioutil.Discard)
+	if err := fs2.ParseArgs([]string{"--upstream", "ftp://up"}); err == nil {
+		t.Errorf("Testing: FlagSet.ParseArgs() after round trip; Expected: error for scheme; Got: no error")
+	}
+	if err := fs2.ParseArgs([]string{"--peers", "c"}); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	if !reflect.DeepEqual(dst["peers"], []HostPort{{"c", "7946"}}) || dst["upstream"] != (*url.URL)(nil) {
+		t.Errorf("Testing: FlagSet.ParseArgs() after round trip; Expected: values in dst; Got: %v", dst)
+	}
+}
+
+func Test_Parse_Units(t *testing.T) {
+	type unitsConfig struct {
+		Buffer int64    `flagparse:"name=--buffer,units=bytes"`
+		Cache  *uint64  `flagparse:"name=--cache,units=bytes"`
+		Rate   float64  `flagparse:"name=--rate,units=si"`
+		Limits []int32  `flagparse:"name=--limits,nargs=-1,units=si"`
+		Pair   [2]int64 `flagparse:"name=--pair,units=bytes"`
+	}
+	cfg := &unitsConfig{Buffer: 64 << 10, Rate: 1500}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+
+	for name, def := range map[string]string{"--buffer": "64KiB", "--cache": "none", "--rate": "1.5k",
+		"--pair": "[0B 0B]"} {
+		if got := fs.Lookup(name).DefValue(); got != def {
+			t.Errorf("Testing: default of %s; Expected: %q; Got: %q", name, def, got)
+		}
+	}
+
+	args := []string{"--buffer", "1MiB", "--cache", "2GB", "--rate", "3M", "--limits", "1k", "2",
+		"--pair", "1KiB", "1K"}
+	if err := fs.ParseArgs(args); err != nil {
+		t.Fatalf("Testing: FlagSet.ParseArgs(%q); Expected: no error; Got: %q", args, err)
+	}
+	if cfg.Buffer != 1<<20 || cfg.Cache == nil || *cfg.Cache != 2e9 || cfg.Rate != 3e6 ||
+		!reflect.DeepEqual(cfg.Limits, []int32{1000, 2}) || cfg.Pair != [2]int64{1024, 1000} {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: all fields set; Got: %+v", args, cfg)
+	}
+
+	for _, args := range [][]string{{"--buffer", "1.5"}, {"--limits", "3G"}, {"--rate", "2KiB"}} {
+		if err := fs.ParseArgs(args); err == nil {
+			t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: error; Got: no error", args)
+		}
+	}
+
+	// units must be part of the schema and survive a round trip
+	if c := fs.Lookup("--buffer").schema().Constraints; !reflect.DeepEqual(c, map[string]string{unitsKey: unitsBytes}) {
+		t.Errorf("Testing: FlagSchema.Constraints of --buffer; Expected: units constraint; Got: %v", c)
+	}
+	dst := make(map[string]interface{})
+	s := &Schema{Optionals: []*FlagSchema{fs.Lookup("--buffer").schema(), fs.Lookup("--limits").schema()}}
+	fs2, err := NewFlagSetFromSchema(s, dst)
+	if err != nil {
+		t.Fatalf("Testing: NewFlagSetFromSchema(); Expected: no error; Got: %q", err)
+	}
+	if dst["buffer"] != int64(64<<10) {
+		t.Errorf("Testing: NewFlagSetFromSchema(); Expected: default %d; Got: %v", 64<<10, dst["buffer"])
+	}
+	fs2.ContinueOnError = true
+	if err := fs2.ParseArgs([]string{"--buffer", "2KiB", "--limits", "2k"}); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	if dst["buffer"] != int64(2048) || !reflect.DeepEqual(dst["limits"], []int32{2000}) {
+		t.Errorf("Testing: FlagSet.ParseArgs() after round trip; Expected: values in dst; Got: %v", dst)
+	}
+}
+
+func Test_Lookup_Visit_VisitAll(t *testing.T) {
+	fs, err := NewFlagSetFrom(&testConfig{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+
+	for _, name := range []string{"pos1", "pos2", "-t", "--opt1", "--opt2", "--opt3", "-s"} {
+		if fl := fs.Lookup(name); fl == nil {
+			t.Errorf("Testing: FlagSet.Lookup(%q); Expected: flag; Got: nil", name)
+		}
+	}
+	if fs.Lookup("-t") != fs.Lookup("--opt1") {
+		t.Errorf("Testing: FlagSet.Lookup(); Expected: same flag for -t and --opt1; Got: different flags")
+	}
+	if fl := fs.Lookup("dummy"); fl != nil {
+		t.Errorf("Testing: FlagSet.Lookup(\"dummy\"); Expected: nil; Got: %v", fl)
+	}
+
+	var all []string
+	fs.VisitAll(func(fl *Flag) { all = append(all, fl.Names()[0]) })
+	expected := []string{"pos1", "pos2", "--opt2", "--opt3", "-s", "-t"}
+	if !reflect.DeepEqual(all, expected) {
+		t.Errorf("Testing: FlagSet.VisitAll(); Expected: %q; Got: %q", expected, all)
+	}
+
+	var set []string
+	fs.Visit(func(fl *Flag) { set = append(set, fl.Names()[0]) })
+	if len(set) != 0 {
+		t.Errorf("Testing: FlagSet.Visit() before parsing; Expected: no flags; Got: %q", set)
+	}
+	if err := fs.ParseArgs([]string{"1", "2", "3", "--opt1", "x", "-s"}); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.Visit(func(fl *Flag) { set = append(set, fl.Names()[0]) })
+	expected = []string{"pos1", "pos2", "-s", "-t"}
+	if !reflect.DeepEqual(set, expected) {
+		t.Errorf("Testing: FlagSet.Visit(); Expected: %q; Got: %q", expected, set)
+	}
+}
+
+func Test_Parse_HelpOption(t *testing.T) {
+	fs, _ := NewFlagSetFrom(&testConfig{})
+	fs.Desc = "flagset description"
+	fs.ContinueOnError = true
+	f, _ := os.Create(os.DevNull)
+	fs.SetOutput(f)
+	fs.CmdArgs = []string{helpLong}
+	err := fs.Parse()
+	if err == nil {
+		t.Errorf("Testing: FlagSet.Parse(); Expected: error with %q args; Got: no error", fs.CmdArgs)
+	}
+	if _, ok := err.(*ErrHelpInvoked); !ok {
+		t.Errorf("Testing: FlagSet.Parse(); Expected: error of type %T with %q args; Got: error of other type", &ErrHelpInvoked{}, fs.CmdArgs)
+	}
+}
+
+func Test_Parse_ExitOnError(t *testing.T) {
+	testParseExit := func() {
+		fs, _ := NewFlagSetFrom(&testConfig{})
+		f, _ := os.Create(os.DevNull)
+		fs.SetOutput(f)
+		fs.CmdArgs = []string{os.Getenv("CMD_ARG")}
+		fs.Parse()
+	}
+	if _, ok := os.LookupEnv("CMD_ARG"); ok {
+		testParseExit()
+		return
+	}
+	data := []struct {
+		arg      string
+		expected int
+	}{
+		{helpLong, 1},
+		{"dummy-flag", 2},
+	}
+	for _, input := range data {
+		cmd := exec.Command(os.Args[0], "-test.run=Test_Parse_ExitOnError")
+		cmd.Env = append(os.Environ(), "CMD_ARG="+input.arg)
+		err := cmd.Run()
+		if e, ok := err.(*exec.ExitError); ok {
+			if e.ExitCode() != input.expected {
+				t.Errorf("Testing: FlagSet.Parse(); Expected: exit code %d with args %q; Got: exit code %d", input.expected, input.arg, e.ExitCode())
+			}
+		}
+	}
+}
+
+func Test_Parse_ExitHook(t *testing.T) {
+	data := []struct {
+		arg      string
+		expected int
+	}{
+		{helpLong, 10},
+		{"dummy-flag", 20},
+	}
+	for _, input := range data {
+		fs, _ := NewFlagSetFrom(&testConfig{})
+		fs.SetOutput(ioutil.Discard)
+		fs.HelpExitCode = 10
+		fs.ErrorExitCode = 20
+		exitCode := -1
+		fs.Exit = func(code int) { exitCode = code }
+		fs.CmdArgs = []string{input.arg}
+		if err := fs.Parse(); err == nil {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: error with %q as args; Got: no error", input.arg)
+		}
+		if exitCode != input.expected {
+			t.Errorf("Testing: FlagSet.Parse(); Expected: exit code %d with args %q; Got: exit code %d", input.expected, input.arg, exitCode)
+		}
+	}
+
+	// Exit is not called when ContinueOnError is set
+	fs, _ := NewFlagSetFrom(&testConfig{})
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+	fs.Exit = func(code int) {
+		t.Errorf("Testing: FlagSet.Parse(); Expected: Exit not called; Got: called with %d", code)
+	}
+	fs.CmdArgs = []string{helpLong}
+	fs.Parse()
+}
+
+func Test_splitKV(t *testing.T) {
+	data := make(map[string][]string)
+	data[""] = []string{}
+	data[fmt.Sprintf("%[1]c%[1]c%[1]c", kvPairSep)] = []string{}
+	data[fmt.Sprintf("a%[1]cb%[1]cc%[1]cd", kvPairSep)] = []string{"a", "b", "c", "d"}
+	data[fmt.Sprintf("%[1]ca%[1]cb%[1]cc%[1]cd%[1]c", kvPairSep)] = []string{"a", "b", "c", "d"}
+	data[fmt.Sprintf("%[1]ca\\%[1]cb%[1]cc%[1]cd\\%[1]c", kvPairSep)] = []string{fmt.Sprintf("a%cb", kvPairSep), "c", fmt.Sprintf("d%c", kvPairSep)}
+	for input, expected := range data {
+		if got := splitKVs(input, kvPairSep); !reflect.DeepEqual(expected, got) {
+			t.Errorf("Testing: splitKV(%q, %c); Expected: %q; Got: %q", input, kvPairSep, expected, got)
+		}
+	}
+}
+
+func Test_splitElems(t *testing.T) {
+	data := map[string][]string{
+		"":             {""},
+		"a,b":          {"a", "b"},
+		"a,,b,":        {"a", "", "b", ""},
+		`a\,b,c`:       {"a,b", "c"},
+		`C:\dir,D:\x`:  {`C:\dir`, `D:\x`},
+		`k\=x=1,\\srv`: {`k\=x=1`, `\\srv`},
+	}
+	for input, expected := range data {
+		if got := splitElems(input, ','); !reflect.DeepEqual(expected, got) {
+			t.Errorf("Testing: splitElems(%q, ','); Expected: %q; Got: %q", input, expected, got)
+		}
+	}
+}
+
+func Test_parseKVs_InvalidKeyValues(t *testing.T) {
+	invalidKVs := []string{
+		"hello",
+		"usage=",
+		"hello=hi",
+		"name=flag_name",
+		"name=flag-name:",
+		"name=:flag-name",
+		"nargs=1x",
+		"nargs=1..",
+		"nargs=-1..3",
+		"optional=yes",
+		"layout=",
+		"dupkeys=first",
+		"sep=",
+		"sep=ab",
+		"defport=",
+		"defport=80:81",
+		"schemes=http:",
+		"schemes=http\\,https",
+		"units=iec",
+	}
+
+	for _, kv := range invalidKVs {
+		if _, err := parseKVs(kv); err == nil {
+			t.Errorf("Testing: parseTags(%q); Expected: error; Got: no error", kv)
+		}
+	}
+}
+
+func Test_parseKVs_ValidKeyValues(t *testing.T) {
+	data := []struct {
+		kvs      string
+		expected map[string]string
+	}{
+		{
+			"",
+			map[string]string{},
+		},
+		{
+			"name=flag-name123,usage=hello\\,world,nargs=10",
+			map[string]string{
+				nargsKey: "10",
+				nameKey:  "flag-name123",
+				usageKey: "hello,world",
+			},
+		},
+		{
+			"name=--range,nargs=2..4",
+			map[string]string{
+				nargsKey: "2..4",
+				nameKey:  "--range",
+			},
+		},
+		{
+			"name=--hosts,sep=\\,",
+			map[string]string{
+				nameKey: "--hosts",
+				sepKey:  ",",
+			},
+		},
+		{
+			"name=--since,layout=Jan 2\\, 2006",
+			map[string]string{
+				nameKey:   "--since",
+				layoutKey: "Jan 2, 2006",
+			},
+		},
+		{
+			"name=-f123:--Flag-Name123,usage=abc,nargs=-10",
+			map[string]string{
+				nargsKey: "-10",
+				nameKey:  "-f123:--Flag-Name123",
+				usageKey: "abc",
+			},
+		},
+	}
+
+	for _, input := range data {
+		got, err := parseKVs(input.kvs)
+		if err != nil {
+			t.Errorf("Testing: parseTags(%q); Expected: no error; Got: %s", input.kvs, err)
+		}
+
+		if !reflect.DeepEqual(input.expected, got) {
+			t.Errorf("Testing: parseTags(%q); Expected: %+v; Got: %+v", input.kvs, input.expected, got)
+		}
+	}
+}
+
+func Test_Parse_Paths(t *testing.T) {
+	root := makePathTree(t)
+	defer os.RemoveAll(root)
+
+	type pathConfig struct {
+		Config string   `flagparse:"name=--config,path=existing-file"`
+		Dir    *string  `flagparse:"name=--dir,path=existing-dir,abs=true"`
+		Out    string   `flagparse:"name=--out,path=not-exist"`
+		Logs   []string `flagparse:"name=--logs,nargs=-1,path=parent-exist"`
+		Home   string   `flagparse:"name=--home,abs=true"`
+	}
+	cfg := &pathConfig{}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.SetOutput(ioutil.Discard)
+	fs.ContinueOnError = true
+
+	args := []string{"--config", filepath.Join(root, "file"), "--dir", filepath.Join(root, "dir", "."),
+		"--out", filepath.Join(root, "out"), "--logs", filepath.Join(root, "a.log"), filepath.Join(root, "dir", "b.log"),
+		"--home", "~"}
+	if err := fs.ParseArgs(args); err != nil {
+		t.Fatalf("Testing: FlagSet.ParseArgs(%q); Expected: no error; Got: %q", args, err)
+	}
+	home, _ := os.UserHomeDir()
+	if cfg.Config != filepath.Join(root, "file") || cfg.Dir == nil || *cfg.Dir != filepath.Join(root, "dir") ||
+		cfg.Out != filepath.Join(root, "out") || cfg.Home != home ||
+		!reflect.DeepEqual(cfg.Logs, []string{filepath.Join(root, "a.log"), filepath.Join(root, "dir", "b.log")}) {
+		t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: all fields set; Got: %+v", args, cfg)
+	}
+
+	for _, args := range [][]string{{"--config", filepath.Join(root, "dir")}, {"--dir", filepath.Join(root, "file")},
+		{"--out", filepath.Join(root, "file")}, {"--logs", filepath.Join(root, "missing", "c.log")}} {
+		if err := fs.ParseArgs(args); err == nil {
+			t.Errorf("Testing: FlagSet.ParseArgs(%q); Expected: error; Got: no error", args)
+		}
+	}
+
+	if _, err := NewFlagSetFrom(&struct {
+		Count int `flagparse:"path=existing-file"`
+	}{}); err == nil {
+		t.Errorf("Testing: NewFlagSetFrom() with path key on int field; Expected: error; Got: no error")
+	}
+
+	// path checks must be part of the schema and survive a round trip
+	expected := map[string]string{pathKey: string(PathExistingDir), absKey: "true"}
+	if c := fs.Lookup("--dir").schema().Constraints; !reflect.DeepEqual(c, expected) {
+		t.Errorf("Testing: FlagSchema.Constraints of --dir; Expected: %v; Got: %v", expected, c)
+	}
+	dst := make(map[string]interface{})
+	s := &Schema{Optionals: []*FlagSchema{fs.Lookup("--config").schema()}}
+	fs2, err := NewFlagSetFromSchema(s, dst)
+	if err != nil {
+		t.Fatalf("Testing: NewFlagSetFromSchema(); Expected: no error; Got: %q", err)
+	}
+	fs2.SetOutput(ioutil.Discard)
+	fs2.ContinueOnError = true
+	if err := fs2.ParseArgs([]string{"--config", filepath.Join(root, "dir")}); err == nil {
+		t.Errorf("Testing: FlagSet.ParseArgs() after round trip; Expected: error for directory; Got: no error")
 	}
 }
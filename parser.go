@@ -0,0 +1,364 @@
+package flagparse
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// parser holds the state of a single parse of a FlagSet's arguments. Keeping this state out of the
+// FlagSet allows the same FlagSet definition to be used by multiple parses concurrently.
+type parser struct {
+	fs *FlagSet
+	// values to set instead of the flags' own values, used when parsing into a destination other
+	// than the variables the flags were created from
+	values      map[*Flag]Value
+	keepUnknown bool
+	posArgs     []cmdArg
+	curFlag     *Flag
+	curFlagName string
+	curFlagPos  int
+	curFlagArgs []string
+	errs        ErrorList
+	set         map[*Flag]bool // flags whose value has been set successfully
+	// arguments given so far to flags whose values accumulate, see accumValue
+	accumArgs map[*Flag][]string
+	// flags whose values accumulate and which failed to be set, later occurrences are ignored so
+	// that the error is reported only once
+	accumFailed map[*Flag]bool
+	// array flags with a separator, in the order of their first occurrence, which are set once all
+	// arguments have been processed, see setArrays
+	arrays []*Flag
+	// name and index of the first occurrence of each flag in arrays
+	arrayPos map[*Flag]cmdArg
+}
+
+func newParser(fs *FlagSet, keepUnknown bool) *parser {
+	return &parser{fs: fs, keepUnknown: keepUnknown, set: make(map[*Flag]bool),
+		accumArgs: make(map[*Flag][]string), accumFailed: make(map[*Flag]bool),
+		arrayPos: make(map[*Flag]cmdArg)}
+}
+
+// setValue sets the Value of fl, given as name at index pos of the arguments, to args and records
+// that fl has been set. Arguments of flags with a separator are split into elements. Such flags and
+// flags whose values accumulate are set to the arguments of all occurrences of fl so far. Array
+// flags with a separator are set by setArrays instead so that the elements of all occurrences fill
+// the array together.
+func (p *parser) setValue(fl *Flag, name string, pos int, args ...string) error {
+	if p.accumFailed[fl] {
+		return nil
+	}
+	orig := append([]string(nil), args...)
+	if fl.sep != 0 {
+		var elems []string
+		for _, arg := range args {
+			elems = append(elems, splitElems(arg, fl.sep)...)
+		}
+		args = elems
+	}
+	av, ok := p.value(fl).(accumValue)
+	accumulates := fl.sep != 0 || (ok && av.accumulates())
+	if accumulates {
+		args = append(append([]string(nil), p.accumArgs[fl]...), args...)
+	}
+	if fl.sep != 0 && isArrayValue(p.value(fl)) {
+		p.accumArgs[fl] = args
+		if _, ok := p.arrayPos[fl]; !ok {
+			p.arrayPos[fl] = cmdArg{name, pos}
+			p.arrays = append(p.arrays, fl)
+		}
+		return nil
+	}
+	// the arguments of earlier occurrences have been set successfully hence an error is due to the
+	// arguments of this occurrence
+	if err := p.value(fl).Set(args...); err != nil {
+		p.accumFailed[fl] = accumulates
+		return &ValueParseError{Flag: name, Pos: pos, Args: orig, Err: err}
+	}
+	if accumulates {
+		p.accumArgs[fl] = args
+	}
+	p.set[fl] = true
+	return nil
+}
+
+// setArrays sets the array flags collected by setValue to the elements of all their occurrences.
+func (p *parser) setArrays() error {
+	for _, fl := range p.arrays {
+		first := p.arrayPos[fl]
+		if err := p.value(fl).Set(p.accumArgs[fl]...); err != nil {
+			err = &ValueParseError{Flag: first.value, Pos: first.index, Args: p.accumArgs[fl], Err: err}
+			if err := p.recordError(err); err != nil {
+				return err
+			}
+			continue
+		}
+		p.set[fl] = true
+	}
+	return nil
+}
+
+// value returns the Value of fl to be set by this parse.
+func (p *parser) value(fl *Flag) Value {
+	if v, ok := p.values[fl]; ok {
+		return v
+	}
+	return fl.value
+}
+
+func (p *parser) writeAndCloseFlag() error {
+	err := p.setValue(p.curFlag, p.curFlagName, p.curFlagPos, p.curFlagArgs...)
+	p.resetCurFlag()
+	return err
+}
+
+func (p *parser) resetCurFlag() {
+	p.curFlagName = ""
+	p.curFlag = nil
+	p.curFlagArgs = p.curFlagArgs[:0]
+}
+
+func (p *parser) processArg(arg string) error {
+	p.curFlagArgs = append(p.curFlagArgs, arg)
+	if _, max := p.curFlag.argRange(); len(p.curFlagArgs) == max {
+		return p.writeAndCloseFlag()
+	}
+	return nil
+}
+
+func (p *parser) closeFlag() error {
+	min, max := p.curFlag.argRange()
+	given := len(p.curFlagArgs)
+	if given < min || (max >= 0 && given > max) {
+		err := &ArgCountError{Flag: p.curFlagName, Pos: p.curFlagPos, Min: min, Max: max,
+			Given: given}
+		p.resetCurFlag()
+		return err
+	}
+	return p.writeAndCloseFlag()
+}
+
+func (p *parser) openFlag(name string, pos int) error {
+	p.curFlagName = name
+	p.curFlagPos = pos
+	p.curFlag = p.fs.optFlags[name]
+	// a switch does not take any arguments hence it can be written right away
+	if _, max := p.curFlag.argRange(); max == 0 {
+		return p.writeAndCloseFlag()
+	}
+	return nil
+}
+
+// cmdArg is a command line argument along with its index in CmdArgs.
+type cmdArg struct {
+	value string
+	index int
+}
+
+func argValues(args []cmdArg) []string {
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = arg.value
+	}
+	return values
+}
+
+// distributePosArgs distributes the collected positional arguments among the positional flags in
+// the order in which they were added. Similar to Python's argparse, each flag greedily takes as
+// many arguments as it can while leaving enough arguments to satisfy the minimum requirement of
+// the flags after it. This allows any number of positional flags with variable nargs, for e.g.
+// "cp SRC... DEST". It returns the arguments which are left over after all positional flags have
+// been satisfied.
+func (p *parser) distributePosArgs() ([]cmdArg, error) {
+	required := 0
+	for _, pf := range p.fs.posFlags {
+		min, _ := pf.flag.argRange()
+		required += min
+	}
+	args := p.posArgs
+	var missing []string
+	for _, pf := range p.fs.posFlags {
+		min, max := pf.flag.argRange()
+		required -= min
+		n := len(args) - required
+		if max >= 0 && n > max {
+			n = max
+		}
+		if n < min {
+			// there are not enough arguments for all positional flags, satisfy them from left to
+			// right for reporting what is missing
+			n = min
+			if n > len(args) {
+				n = len(args)
+			}
+			if n == 0 {
+				missing = append(missing, pf.name)
+				continue
+			}
+			if n < min {
+				err := &ArgCountError{Flag: pf.name, Pos: args[0].index, Min: min, Max: max, Given: n}
+				if err := p.recordError(err); err != nil {
+					return nil, err
+				}
+				args = args[n:]
+				continue
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		// an optional flag may be omitted but once given it needs as many arguments as its nargs
+		if givenMin, _ := pf.flag.givenArgRange(); n < givenMin {
+			err := &ArgCountError{Flag: pf.name, Pos: args[0].index, Min: givenMin, Max: max, Given: n}
+			if err := p.recordError(err); err != nil {
+				return nil, err
+			}
+			args = args[n:]
+			continue
+		}
+		values := argValues(args[:n])
+		if err := p.setValue(pf.flag, pf.name, args[0].index, values...); err != nil {
+			if err := p.recordError(err); err != nil {
+				return nil, err
+			}
+		}
+		args = args[n:]
+	}
+	if len(missing) > 0 {
+		if err := p.recordError(&MissingRequiredError{Flags: missing}); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+// parse processes args. If keepUnknown is set then unrecognized optional flags and arguments left
+// over after satisfying all positional flags are returned in their original order instead of
+// resulting in error.
+func (p *parser) parse(args []string) ([]string, error) {
+	fs := p.fs
+	var unknown []cmdArg
+	for i, curArg := range args {
+		// does it looks like an optional flag?
+		if strings.HasPrefix(curArg, defaultOptPrefix) {
+			// is it a help flag?
+			if curArg == helpShort || curArg == helpLong {
+				return nil, &ErrHelpInvoked{}
+			}
+			name, err := fs.lookupOptName(curArg, i)
+			// if this is not a known flag then return error
+			if err == nil && name == "" && !p.keepUnknown {
+				err = &UnknownFlagError{Name: curArg, Pos: i,
+					Candidates: fs.suggestOptNames(curArg, false)}
+			}
+			if err := p.recordError(err); err != nil {
+				return nil, err
+			}
+			// before starting to process this flag, try closing the current opt flag if any
+			if p.curFlagName != "" {
+				if err := p.recordError(p.closeFlag()); err != nil {
+					return nil, err
+				}
+			}
+			if name == "" {
+				if p.keepUnknown {
+					unknown = append(unknown, cmdArg{curArg, i})
+				}
+				continue
+			}
+			// since this is a known opt flag, no flag is opened currently, open this flag for
+			// processing
+			if err := p.recordError(p.openFlag(name, i)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// if there is an opt flag open then process current argument for it
+		if p.curFlagName != "" {
+			if err := p.recordError(p.processArg(curArg)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// since there is no opt flag open, current argument is meant for positional flags. Collect
+		// it so that all such arguments can be distributed once every argument has been seen.
+		p.posArgs = append(p.posArgs, cmdArg{curArg, i})
+		if fs.StopAtFirstPositional {
+			for j, arg := range args[i+1:] {
+				p.posArgs = append(p.posArgs, cmdArg{arg, i + 1 + j})
+			}
+			break
+		}
+	}
+	if p.curFlagName != "" {
+		if err := p.recordError(p.closeFlag()); err != nil {
+			return nil, err
+		}
+	}
+	leftover, err := p.distributePosArgs()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.setArrays(); err != nil {
+		return nil, err
+	}
+	if !p.keepUnknown {
+		// since all positional flags have been satisfied, remaining arguments are
+		// unwanted/unrecognized
+		for _, arg := range leftover {
+			err := &UnknownFlagError{Name: arg.value, Pos: arg.index, Argument: true,
+				Candidates: fs.suggestOptNames(arg.value, true)}
+			if err := p.recordError(err); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(p.errs) > 0 {
+		return nil, p.errs
+	}
+	if !p.keepUnknown {
+		return nil, nil
+	}
+	unknown = append(unknown, leftover...)
+	sort.SliceStable(unknown, func(i, j int) bool { return unknown[i].index < unknown[j].index })
+	return argValues(unknown), nil
+}
+
+// recordError returns err as is unless CollectErrors is set, in which case err is added to the
+// collected errors and nil is returned so that parsing can continue.
+func (p *parser) recordError(err error) error {
+	if err == nil || !p.fs.CollectErrors {
+		return err
+	}
+	p.errs = append(p.errs, err)
+	return nil
+}
+
+// splitElems splits arg at every sep which is not preceded by a backslash. Only the backslashes
+// escaping a sep are removed, other backslashes and empty elements are kept as is, for e.g.
+// "C:\\dir,,a\\,b" results in "C:\\dir", "" and "a,b".
+func splitElems(arg string, sep rune) []string {
+	var elems []string
+	b := &strings.Builder{}
+	for i, r := range arg {
+		switch {
+		case r == '\\' && strings.HasPrefix(arg[i+1:], string(sep)):
+			// the escaped sep is written in the next iteration
+		case r == sep && !strings.HasSuffix(arg[:i], "\\"):
+			elems = append(elems, b.String())
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return append(elems, b.String())
+}
+
+// isArrayValue reports whether val holds an array or a pointer to an array.
+func isArrayValue(val Value) bool {
+	typ := reflect.TypeOf(val.Get())
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ != nil && typ.Kind() == reflect.Array
+}
@@ -0,0 +1,206 @@
+package flagparse
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// PathCheck specifies the condition a path given as argument must satisfy.
+type PathCheck string
+
+const (
+	// PathAny accepts any path.
+	PathAny PathCheck = "any"
+	// PathExistingFile accepts paths of existing, readable files which are not directories.
+	PathExistingFile PathCheck = "existing-file"
+	// PathExistingDir accepts paths of existing, readable directories.
+	PathExistingDir PathCheck = "existing-dir"
+	// PathNotExist accepts paths which do not exist yet and whose parent directory, if it exists, is
+	// writable.
+	PathNotExist PathCheck = "not-exist"
+	// PathParentExist accepts paths whose parent directory exists and is writable.
+	PathParentExist PathCheck = "parent-exist"
+)
+
+// pathValue wraps a Value of a string type, or a slice of strings, so that each argument is treated
+// as a file system path. A leading "~" is expanded to the user's home directory, the path is made
+// absolute if required and then checked as per check.
+type pathValue struct {
+	Value
+	value reflect.Value // the addressable string or slice of strings
+	check PathCheck
+	abs   bool
+}
+
+func newPathValue(val Value, check PathCheck, abs bool) (*pathValue, error) {
+	v := reflect.ValueOf(valueTarget(val))
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	typ := reflect.TypeOf(val.Get())
+	if v.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.String {
+		return nil, fmt.Errorf("key '%s' is not supported for type '%T'", pathKey, val.Get())
+	}
+	if check == "" {
+		check = PathAny
+	}
+	return &pathValue{Value: val, value: v, check: check, abs: abs}, nil
+}
+
+func (pv *pathValue) Set(values ...string) error {
+	if pv.value.Kind() != reflect.Slice {
+		if len(values) == 0 {
+			return nil
+		}
+		path, err := pv.parse(values[0])
+		if err != nil {
+			return err
+		}
+		pv.value.SetString(path)
+		return nil
+	}
+	list := reflect.MakeSlice(pv.value.Type(), len(values), len(values))
+	for i, val := range values {
+		path, err := pv.parse(val)
+		if err != nil {
+			return err
+		}
+		list.Index(i).SetString(path)
+	}
+	pv.value.Set(list)
+	return nil
+}
+
+// parse returns the expanded form of path after making sure that it satisfies the check.
+func (pv *pathValue) parse(path string) (string, error) {
+	p, err := expandPath(path, pv.abs)
+	if err == nil {
+		err = checkPath(p, pv.check)
+	}
+	if err != nil {
+		return "", formatParseError(path, "path", err)
+	}
+	return p, nil
+}
+
+// expandPath replaces a leading "~" in path with the user's home directory and makes the path
+// absolute if abs is true.
+func expandPath(path string, abs bool) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("empty path")
+	}
+	if path == "~" || strings.HasPrefix(path, "~"+string(filepath.Separator)) || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if abs {
+		return filepath.Abs(path)
+	}
+	return path, nil
+}
+
+// checkPath returns error if path does not satisfy check. Besides existence, existing files and
+// directories must be readable and the parent directory of a path which is to be created must be
+// writable.
+func checkPath(path string, check PathCheck) error {
+	switch check {
+	case PathExistingFile:
+		info, err := os.Stat(path)
+		if err != nil {
+			return pathError(err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("is a directory")
+		}
+		// opening a named pipe or the like might block, so only regular files are tried
+		if info.Mode().IsRegular() {
+			return checkReadable(path)
+		}
+	case PathExistingDir:
+		info, err := os.Stat(path)
+		if err != nil {
+			return pathError(err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("not a directory")
+		}
+		return checkReadable(path)
+	case PathNotExist:
+		_, err := os.Lstat(path)
+		if err == nil {
+			return fmt.Errorf("already exists")
+		}
+		if !os.IsNotExist(err) {
+			return pathError(err)
+		}
+		// the parent need not exist yet, but if it does the path must be creatable in it
+		if info, err := os.Stat(filepath.Dir(path)); err == nil && info.IsDir() {
+			if err := checkWritable(filepath.Dir(path)); err != nil {
+				return fmt.Errorf("parent directory: %s", err)
+			}
+		}
+	case PathParentExist:
+		info, err := os.Stat(filepath.Dir(path))
+		if err != nil {
+			return fmt.Errorf("parent directory: %s", pathError(err))
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("parent is not a directory")
+		}
+		if err := checkWritable(filepath.Dir(path)); err != nil {
+			return fmt.Errorf("parent directory: %s", err)
+		}
+	}
+	return nil
+}
+
+// checkReadable returns error if the file or directory path cannot be opened for reading.
+func checkReadable(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return pathError(err)
+	}
+	return f.Close()
+}
+
+// checkWritable returns error if no file can be created in the directory dir. This is tried by
+// creating and removing a temporary file since permission bits alone do not tell, for e.g. on
+// read-only file systems or with ACLs.
+func checkWritable(dir string) error {
+	f, err := ioutil.TempFile(dir, ".flagparse")
+	if err != nil {
+		return pathError(err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// pathError strips the operation and path from err, since the path is part of the error message
+// anyway, for e.g. leaving "no such file or directory".
+func pathError(err error) error {
+	if inner := errors.Unwrap(err); inner != nil {
+		return inner
+	}
+	return err
+}
+
+func (pv *pathValue) target() interface{} { return pv.value.Addr().Interface() }
+
+func (pv *pathValue) constraints() map[string]string {
+	c := map[string]string{pathKey: string(pv.check)}
+	if pv.abs {
+		c[absKey] = "true"
+	}
+	return c
+}
@@ -0,0 +1,173 @@
+package flagparse
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// makePathTree creates a temporary directory holding a file "file" and a directory "dir".
+func makePathTree(t *testing.T) string {
+	root, err := ioutil.TempDir("", "flagparse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "file"), nil, 0600); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "dir"), 0700); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	return root
+}
+
+func Test_expandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home directory: %v", err)
+	}
+	wd, _ := os.Getwd()
+	valid := []struct {
+		path     string
+		abs      bool
+		expected string
+	}{
+		{"~", false, home},
+		{"~/a/b", false, filepath.Join(home, "a", "b")},
+		{"~user/a", false, "~user/a"},
+		{"a/../b", false, "a/../b"},
+		{"a/../b", true, filepath.Join(wd, "b")},
+		{"/a/./b", true, "/a/b"},
+	}
+	for _, input := range valid {
+		got, err := expandPath(input.path, input.abs)
+		if err != nil || got != input.expected {
+			t.Errorf("Testing: expandPath(%q, %v); Expected: %q; Got: %q, error %v", input.path, input.abs,
+				input.expected, got, err)
+		}
+	}
+	if _, err := expandPath("", false); err == nil {
+		t.Errorf("Testing: expandPath(\"\", false); Expected: error; Got: no error")
+	}
+}
+
+func Test_checkPath(t *testing.T) {
+	root := makePathTree(t)
+	defer os.RemoveAll(root)
+	file, dir, missing := filepath.Join(root, "file"), filepath.Join(root, "dir"), filepath.Join(root, "missing")
+
+	valid := []struct {
+		path  string
+		check PathCheck
+	}{
+		{missing, PathAny},
+		{file, PathExistingFile},
+		{dir, PathExistingDir},
+		{missing, PathNotExist},
+		{missing, PathParentExist},
+		{filepath.Join(dir, "new"), PathParentExist},
+	}
+	for _, input := range valid {
+		if err := checkPath(input.path, input.check); err != nil {
+			t.Errorf("Testing: checkPath(%q, %q); Expected: no error; Got: %q", input.path, input.check, err)
+		}
+	}
+
+	invalid := []struct {
+		path  string
+		check PathCheck
+	}{
+		{missing, PathExistingFile},
+		{dir, PathExistingFile},
+		{missing, PathExistingDir},
+		{file, PathExistingDir},
+		{file, PathNotExist},
+		{dir, PathNotExist},
+		{filepath.Join(missing, "new"), PathParentExist},
+		{filepath.Join(file, "new"), PathParentExist},
+	}
+	for _, input := range invalid {
+		if err := checkPath(input.path, input.check); err == nil {
+			t.Errorf("Testing: checkPath(%q, %q); Expected: error; Got: no error", input.path, input.check)
+		}
+	}
+}
+
+func Test_checkPath_Permissions(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	root := makePathTree(t)
+	defer os.RemoveAll(root)
+	file, dir := filepath.Join(root, "file"), filepath.Join(root, "dir")
+	if err := os.Chmod(file, 0200); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	if err := os.Chmod(dir, 0300); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	locked := filepath.Join(root, "locked")
+	if err := os.Mkdir(locked, 0500); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	defer os.Chmod(locked, 0700)
+
+	invalid := []struct {
+		path  string
+		check PathCheck
+	}{
+		{file, PathExistingFile},
+		{dir, PathExistingDir},
+		{filepath.Join(locked, "new"), PathNotExist},
+		{filepath.Join(locked, "new"), PathParentExist},
+	}
+	for _, input := range invalid {
+		if err := checkPath(input.path, input.check); err == nil {
+			t.Errorf("Testing: checkPath(%q, %q); Expected: error; Got: no error", input.path, input.check)
+		}
+	}
+	// a writable but unreadable directory still allows creating paths in it
+	if err := checkPath(filepath.Join(dir, "new"), PathParentExist); err != nil {
+		t.Errorf("Testing: checkPath(%q, %q); Expected: no error; Got: %q", filepath.Join(dir, "new"),
+			PathParentExist, err)
+	}
+	if names, _ := ioutil.ReadDir(locked); len(names) != 0 {
+		t.Errorf("Testing: checkPath(); Expected: no files left behind; Got: %d", len(names))
+	}
+}
+
+func TestPathType(t *testing.T) {
+	root := makePathTree(t)
+	defer os.RemoveAll(root)
+
+	var s string
+	val, _ := newPathValue(newStringValue(&s), PathExistingFile, false)
+	if err := val.Set(filepath.Join(root, "file")); err != nil || s != filepath.Join(root, "file") {
+		t.Errorf("Testing: pathValue.Set(); Expected: %q; Got: %q, error %v", filepath.Join(root, "file"), s, err)
+	}
+	err := val.Set(filepath.Join(root, "dir"))
+	expected := "cannot parse '" + filepath.Join(root, "dir") + "' as type 'path': is a directory"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Testing: pathValue.Set(); Expected: error %q; Got: %v", expected, err)
+	}
+	if !reflect.DeepEqual(val.constraints(), map[string]string{pathKey: string(PathExistingFile)}) {
+		t.Errorf("Testing: pathValue.constraints(); Expected: path constraint; Got: %v", val.constraints())
+	}
+
+	var list []string
+	listVal, _ := newPathValue(newStringListValue(&list), PathParentExist, true)
+	args := []string{filepath.Join(root, "a"), filepath.Join(root, "dir", "..", "b")}
+	if err := listVal.Set(args...); err != nil ||
+		!reflect.DeepEqual(list, []string{filepath.Join(root, "a"), filepath.Join(root, "b")}) {
+		t.Errorf("Testing: pathValue.Set(%q); Expected: cleaned paths; Got: %q, error %v", args, list, err)
+	}
+	if err := listVal.Set(filepath.Join(root, "missing", "a")); err == nil {
+		t.Errorf("Testing: pathValue.Set(); Expected: error for missing parent; Got: no error")
+	}
+
+	if _, err := newPathValue(newIntValue(new(int)), PathAny, false); err == nil {
+		t.Errorf("Testing: newPathValue() with int value; Expected: error; Got: no error")
+	}
+}
@@ -0,0 +1,294 @@
+package flagparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is a serialisable description of a FlagSet. It allows external tools like documentation
+// generators and GUIs to learn about the flags of a program without importing its Go packages.
+type Schema struct {
+	Name        string        `json:"name"`
+	Desc        string        `json:"desc,omitempty"`
+	Positionals []*FlagSchema `json:"positionals"`
+	Optionals   []*FlagSchema `json:"optionals"`
+}
+
+// FlagSchema is a serialisable description of a Flag.
+type FlagSchema struct {
+	// Names contains the name of a positional flag or all names of an optional flag, primary name
+	// first.
+	Names []string `json:"names"`
+	// Type is the Go type of the flag's value, for e.g. "int" or "[]string".
+	Type string `json:"type"`
+	// MinArgs and MaxArgs are the minimum and maximum number of arguments the flag accepts, a
+	// negative MaxArgs means there is no upper limit.
+	MinArgs int    `json:"minArgs"`
+	MaxArgs int    `json:"maxArgs"`
+	Default string `json:"default"`
+	Usage   string `json:"usage,omitempty"`
+	// Optional is true for positional flags which may be omitted.
+	Optional bool `json:"optional,omitempty"`
+	// Constraints contains any further restrictions on the flag's arguments keyed by the struct
+	// tag key used to specify them.
+	Constraints map[string]string `json:"constraints,omitempty"`
+}
+
+// constrainedValue is implemented by Value types which restrict the arguments they accept beyond
+// their type, so that the restrictions can be included in a Schema.
+type constrainedValue interface {
+	constraints() map[string]string
+}
+
+// Schema returns a description of the FlagSet and all of its flags.
+func (fs *FlagSet) Schema() *Schema {
+	s := &Schema{
+		Name:        fs.name,
+		Desc:        fs.Desc,
+		Positionals: []*FlagSchema{},
+		Optionals:   []*FlagSchema{},
+	}
+	for _, fl := range fs.allFlags() {
+		if fl.positional {
+			s.Positionals = append(s.Positionals, fl.schema())
+		} else {
+			s.Optionals = append(s.Optionals, fl.schema())
+		}
+	}
+	return s
+}
+
+func (fl *Flag) schema() *FlagSchema {
+	min, max := fl.argRange()
+	fls := &FlagSchema{
+		Names:    fl.Names(),
+		Type:     fmt.Sprintf("%T", fl.value.Get()),
+		MinArgs:  min,
+		MaxArgs:  max,
+		Default:  fl.defVal,
+		Usage:    fl.usage,
+		Optional: fl.optional,
+	}
+	if cv, ok := fl.value.(constrainedValue); ok {
+		fls.Constraints = cv.constraints()
+	}
+	if fl.sep != 0 {
+		if fls.Constraints == nil {
+			fls.Constraints = make(map[string]string)
+		}
+		fls.Constraints[sepKey] = string(fl.sep)
+	}
+	return fls
+}
+
+// WriteJSON writes the schema to w as indented JSON.
+func (s *Schema) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// schemaTypes maps the type names usable in a FlagSchema to their Go types.
+var schemaTypes = map[string]reflect.Type{
+	"bool":      reflect.TypeOf(false),
+	"[]bool":    reflect.TypeOf([]bool{}),
+	"string":    reflect.TypeOf(""),
+	"[]string":  reflect.TypeOf([]string{}),
+	"int":       reflect.TypeOf(0),
+	"[]int":     reflect.TypeOf([]int{}),
+	"int8":      reflect.TypeOf(int8(0)),
+	"[]int8":    reflect.TypeOf([]int8{}),
+	"int16":     reflect.TypeOf(int16(0)),
+	"[]int16":   reflect.TypeOf([]int16{}),
+	"int32":     reflect.TypeOf(int32(0)),
+	"[]int32":   reflect.TypeOf([]int32{}),
+	"int64":     reflect.TypeOf(int64(0)),
+	"[]int64":   reflect.TypeOf([]int64{}),
+	"uint":      reflect.TypeOf(uint(0)),
+	"[]uint":    reflect.TypeOf([]uint{}),
+	"uint8":     reflect.TypeOf(uint8(0)),
+	"[]uint8":   reflect.TypeOf([]uint8{}),
+	"uint16":    reflect.TypeOf(uint16(0)),
+	"[]uint16":  reflect.TypeOf([]uint16{}),
+	"uint32":    reflect.TypeOf(uint32(0)),
+	"[]uint32":  reflect.TypeOf([]uint32{}),
+	"uint64":    reflect.TypeOf(uint64(0)),
+	"[]uint64":  reflect.TypeOf([]uint64{}),
+	"uintptr":   reflect.TypeOf(uintptr(0)),
+	"[]uintptr": reflect.TypeOf([]uintptr{}),
+	"float64":   reflect.TypeOf(float64(0)),
+	"[]float64": reflect.TypeOf([]float64{}),
+
+	"time.Duration":   reflect.TypeOf(time.Duration(0)),
+	"[]time.Duration": reflect.TypeOf([]time.Duration{}),
+	"time.Time":       reflect.TypeOf(time.Time{}),
+	"[]time.Time":     reflect.TypeOf([]time.Time{}),
+
+	"net.IP":               reflect.TypeOf(net.IP{}),
+	"[]net.IP":             reflect.TypeOf([]net.IP{}),
+	"net.IPNet":            reflect.TypeOf(net.IPNet{}),
+	"[]net.IPNet":          reflect.TypeOf([]net.IPNet{}),
+	"flagparse.HostPort":   reflect.TypeOf(HostPort{}),
+	"[]flagparse.HostPort": reflect.TypeOf([]HostPort{}),
+	"url.URL":              reflect.TypeOf(url.URL{}),
+	"*url.URL":             reflect.TypeOf(&url.URL{}),
+	"[]*url.URL":           reflect.TypeOf([]*url.URL{}),
+}
+
+// NewFlagSetFromSchema creates a FlagSet as described by s. This is the inverse of FlagSet.Schema
+// and allows declaring flags without Go structs, for e.g. for script based plugins. Values of the
+// flags are collected in dst keyed by the primary name of each flag without the "-" prefix.
+// Every key is initialized with the flag's default value. Supported types are the built-in types
+// listed in the package documentation and their slices. Default values for slices are given like
+// "[1 2 3]" i.e. space separated elements within brackets. It returns error if two flags would be
+// stored under the same key, for e.g. "src" and "--src".
+func NewFlagSetFromSchema(s *Schema, dst map[string]interface{}) (*FlagSet, error) {
+	if s == nil || dst == nil {
+		return nil, &DefinitionError{Err: fmt.Errorf("schema and dst cannot be nil")}
+	}
+	fs := NewFlagSet()
+	if s.Name != "" {
+		fs.name = s.Name
+	}
+	fs.Desc = s.Desc
+	keys := make(map[string]string)
+	for i, fls := range append(append([]*FlagSchema(nil), s.Positionals...), s.Optionals...) {
+		if fls == nil || len(fls.Names) == 0 {
+			return nil, &DefinitionError{Err: fmt.Errorf("flag schema must have at least one name")}
+		}
+		key := schemaKey(fls.Names[0])
+		if other, ok := keys[key]; ok {
+			return nil, &DefinitionError{Flag: fls.Names[0],
+				Err: fmt.Errorf("flags %s and %s would both be stored under key %q", other, fls.Names[0], key)}
+		}
+		keys[key] = fls.Names[0]
+		fl, err := fls.newFlag(dst, i < len(s.Positionals))
+		if err != nil {
+			return nil, &DefinitionError{Flag: fls.Names[0], Err: err}
+		}
+		if err := fs.Add(fl, fls.Names[0], fls.Names[1:]...); err != nil {
+			return nil, err
+		}
+	}
+	return fs, nil
+}
+
+// NewFlagSetFromJSON is like NewFlagSetFromSchema but reads the schema as JSON from r, in the
+// format written by Schema.WriteJSON.
+func NewFlagSetFromJSON(r io.Reader, dst map[string]interface{}) (*FlagSet, error) {
+	s := &Schema{}
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, &DefinitionError{Err: fmt.Errorf("cannot decode schema: %s", err)}
+	}
+	return NewFlagSetFromSchema(s, dst)
+}
+
+func (fls *FlagSchema) newFlag(dst map[string]interface{}, positional bool) (*Flag, error) {
+	typ, ok := schemaTypes[fls.Type]
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %q", fls.Type)
+	}
+	for key := range fls.Constraints {
+		if !valueKeys[key] && key != sepKey {
+			return nil, fmt.Errorf("unsupported constraint %q", key)
+		}
+	}
+	val, err := newValue(reflect.New(typ).Interface())
+	if err != nil {
+		return nil, err
+	}
+	val, err = applyValueKeys(val, fls.Constraints)
+	if err != nil {
+		return nil, err
+	}
+	if err := setSchemaDefault(val, typ, fls.Default); err != nil {
+		return nil, fmt.Errorf("invalid default value: %s", err)
+	}
+	fl := NewFlag(newMapEntryValue(val, dst, schemaKey(fls.Names[0])), positional, fls.Usage)
+	min := fls.MinArgs
+	// MinArgs of an optional positional flag is 0 due to it being optional, the flag itself still
+	// needs at least one argument when given
+	if fls.Optional && positional && min == 0 {
+		min = 1
+	}
+	if err := fl.SetNArgsRange(min, fls.MaxArgs); err != nil {
+		return nil, err
+	}
+	if fls.Optional {
+		if err := fl.SetOptional(true); err != nil {
+			return nil, err
+		}
+	}
+	if sep := []rune(fls.Constraints[sepKey]); len(sep) == 1 {
+		if err := fl.SetSeparator(sep[0]); err != nil {
+			return nil, err
+		}
+	} else if len(sep) > 1 {
+		return nil, fmt.Errorf("separator must be a single character: %q", string(sep))
+	}
+	return fl, nil
+}
+
+// schemaKey returns the key in dst under which the value of the flag with the given primary name is
+// stored.
+func schemaKey(name string) string {
+	return strings.TrimLeft(name, defaultOptPrefix)
+}
+
+// setSchemaDefault sets val, of type typ, to the default value def given in a FlagSchema.
+func setSchemaDefault(val Value, typ reflect.Type, def string) error {
+	if typ.Kind() == reflect.Slice {
+		def = strings.TrimSuffix(strings.TrimPrefix(def, "["), "]")
+		return val.Set(strings.Fields(def)...)
+	}
+	// a nil pointer is shown as "none"
+	if def == "" || (typ.Kind() == reflect.Ptr && def == "none") {
+		return nil
+	}
+	return val.Set(def)
+}
+
+// mapEntryValue wraps a Value and keeps the value of the underlying variable stored in a map entry.
+type mapEntryValue struct {
+	Value
+	dst    map[string]interface{}
+	key    string
+	defVal reflect.Value
+}
+
+func newMapEntryValue(val Value, dst map[string]interface{}, key string) *mapEntryValue {
+	mv := &mapEntryValue{Value: val, dst: dst, key: key, defVal: copyPointee(valueTarget(val))}
+	dst[key] = val.Get()
+	return mv
+}
+
+func (mv *mapEntryValue) Set(values ...string) error {
+	if err := mv.Value.Set(values...); err != nil {
+		return err
+	}
+	mv.dst[mv.key] = mv.Value.Get()
+	return nil
+}
+
+// reset restores the wrapped Value and the map entry to the default value.
+func (mv *mapEntryValue) reset() {
+	if r, ok := mv.Value.(interface{ reset() }); ok {
+		r.reset()
+	} else {
+		reflect.ValueOf(valueTarget(mv.Value)).Elem().Set(copyReflectValue(mv.defVal))
+	}
+	mv.dst[mv.key] = mv.Value.Get()
+}
+
+func (mv *mapEntryValue) constraints() map[string]string {
+	if cv, ok := mv.Value.(constrainedValue); ok {
+		return cv.constraints()
+	}
+	return nil
+}
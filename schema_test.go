@@ -0,0 +1,186 @@
+package flagparse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Schema(t *testing.T) {
+	cfg := &struct {
+		Src  []string `flagparse:"nargs=-1,usage=source files"`
+		Dest string   `flagparse:"optional=true"`
+		Opt1 []int    `flagparse:"name=--opt1:-o,nargs=2..3,usage=opt1 usage"`
+		Sw1  bool     `flagparse:"name=-s,nargs=0"`
+	}{Dest: ".", Opt1: []int{1, 2}}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	fs.name = "cp"
+	fs.Desc = "copy files"
+
+	expected := &Schema{
+		Name: "cp",
+		Desc: "copy files",
+		Positionals: []*FlagSchema{
+			{Names: []string{"src"}, Type: "[]string", MinArgs: 1, MaxArgs: -1, Default: "[]", Usage: "source files"},
+			{Names: []string{"dest"}, Type: "string", MinArgs: 0, MaxArgs: 1, Default: ".", Optional: true},
+		},
+		Optionals: []*FlagSchema{
+			{Names: []string{"--opt1", "-o"}, Type: "[]int", MinArgs: 2, MaxArgs: 3, Default: "[1 2]", Usage: "opt1 usage"},
+			{Names: []string{"-s"}, Type: "bool", MinArgs: 0, MaxArgs: 0, Default: ""},
+		},
+	}
+	got := fs.Schema()
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Testing: FlagSet.Schema(); Expected: %+v; Got: %+v", expected, got)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := got.WriteJSON(buf); err != nil {
+		t.Fatalf("Testing: Schema.WriteJSON(); Expected: no error; Got: %v", err)
+	}
+	decoded := &Schema{}
+	if err := json.Unmarshal(buf.Bytes(), decoded); err != nil {
+		t.Fatalf("Testing: Schema.WriteJSON(); Expected: valid JSON; Got: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("Testing: Schema.WriteJSON(); Expected: %+v after decoding; Got: %+v", expected, decoded)
+	}
+}
+
+func Test_NewFlagSetFromJSON(t *testing.T) {
+	spec := `{
+		"name": "plugin",
+		"desc": "a plugin",
+		"positionals": [
+			{"names": ["files"], "type": "[]string", "minArgs": 1, "maxArgs": -1},
+			{"names": ["mode"], "type": "string", "minArgs": 0, "maxArgs": 1, "default": "fast", "optional": true}
+		],
+		"optionals": [
+			{"names": ["--count", "-c"], "type": "int", "minArgs": 1, "maxArgs": 1, "default": "3"},
+			{"names": ["--ratios"], "type": "[]float64", "minArgs": 1, "maxArgs": 2, "default": "[0.5 1.5]"},
+			{"names": ["-v"], "type": "bool", "minArgs": 0, "maxArgs": 0}
+		]
+	}`
+	dst := make(map[string]interface{})
+	fs, err := NewFlagSetFromJSON(bytes.NewBufferString(spec), dst)
+	if err != nil {
+		t.Fatalf("Testing: NewFlagSetFromJSON(); Expected: no error; Got: %v", err)
+	}
+	expected := map[string]interface{}{
+		"files":  []string{},
+		"mode":   "fast",
+		"count":  3,
+		"ratios": []float64{0.5, 1.5},
+		"v":      false,
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Testing: NewFlagSetFromJSON(); Expected: defaults %v; Got: %v", expected, dst)
+	}
+
+	fs.ContinueOnError = true
+	if err := fs.ParseArgs([]string{"a", "b", "-c", "7", "-v", "--ratios", "2.5"}); err != nil {
+		t.Fatalf("Testing: FlagSet.ParseArgs(); Expected: no error; Got: %v", err)
+	}
+	expected = map[string]interface{}{
+		"files":  []string{"a", "b"},
+		"mode":   "fast",
+		"count":  7,
+		"ratios": []float64{2.5},
+		"v":      true,
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Testing: FlagSet.ParseArgs(); Expected: %v; Got: %v", expected, dst)
+	}
+
+	fs.Reset()
+	if dst["count"] != 3 || dst["v"] != false {
+		t.Errorf("Testing: FlagSet.Reset(); Expected: defaults to be restored; Got: %v", dst)
+	}
+
+	// the schema of the created FlagSet describes the same flags
+	if s := fs.Schema(); len(s.Positionals) != 2 || len(s.Optionals) != 3 || s.Name != "plugin" {
+		t.Errorf("Testing: FlagSet.Schema(); Expected: schema matching spec; Got: %+v", s)
+	}
+}
+
+func Test_Schema_TimeLayout(t *testing.T) {
+	cfg := &struct {
+		Since time.Time     `flagparse:"name=--since,layout=2006-01-02"`
+		Wait  time.Duration `flagparse:"name=--wait"`
+	}{Since: time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), Wait: time.Minute}
+	fs, err := NewFlagSetFrom(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	s := fs.Schema()
+	expected := []*FlagSchema{
+		{Names: []string{"--since"}, Type: "time.Time", MinArgs: 1, MaxArgs: 1, Default: "2020-02-01",
+			Constraints: map[string]string{layoutKey: "2006-01-02"}},
+		{Names: []string{"--wait"}, Type: "time.Duration", MinArgs: 1, MaxArgs: 1, Default: "1m0s"},
+	}
+	if !reflect.DeepEqual(s.Optionals, expected) {
+		t.Errorf("Testing: FlagSet.Schema(); Expected: %+v; Got: %+v", expected, s.Optionals)
+	}
+
+	// the layout must survive a round trip through the schema
+	dst := make(map[string]interface{})
+	fs, err = NewFlagSetFromSchema(s, dst)
+	if err != nil {
+		t.Fatalf("Testing: NewFlagSetFromSchema(); Expected: no error; Got: %q", err)
+	}
+	if !reflect.DeepEqual(fs.Schema().Optionals, expected) {
+		t.Errorf("Testing: FlagSet.Schema() after round trip; Expected: %+v; Got: %+v", expected, fs.Schema().Optionals)
+	}
+	fs.ContinueOnError = true
+	if err := fs.ParseArgs([]string{"--since", "2021-03-04", "--wait", "2s"}); err != nil {
+		t.Fatalf("Unexpected error: %q", err)
+	}
+	if dst["since"] != time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC) || dst["wait"] != 2*time.Second {
+		t.Errorf("Testing: FlagSet.ParseArgs(); Expected: values parsed into dst; Got: %v", dst)
+	}
+}
+
+func Test_NewFlagSetFromSchema_Invalid(t *testing.T) {
+	data := []*Schema{
+		nil,
+		{Positionals: []*FlagSchema{{Type: "int"}}},
+		{Positionals: []*FlagSchema{{Names: []string{"pos"}, Type: "complex128", MinArgs: 1, MaxArgs: 1}}},
+		{Positionals: []*FlagSchema{{Names: []string{"pos"}, Type: "int", MinArgs: 0, MaxArgs: 0}}},
+		{Optionals: []*FlagSchema{{Names: []string{"--opt"}, Type: "int", MinArgs: 1, MaxArgs: 1, Default: "x"}}},
+		{Optionals: []*FlagSchema{{Names: []string{"--opt"}, Type: "int", MinArgs: 1, MaxArgs: 1, Optional: true}}},
+		{Optionals: []*FlagSchema{{Names: []string{"no-prefix", "-o"}, Type: "int", MinArgs: 1, MaxArgs: 1}}},
+		{Optionals: []*FlagSchema{{Names: []string{"--opt"}, Type: "time.Time", MinArgs: 1, MaxArgs: 1,
+			Constraints: map[string]string{"unknown": "x"}}}},
+	}
+	for _, input := range data {
+		if fs, err := NewFlagSetFromSchema(input, make(map[string]interface{})); err == nil {
+			t.Errorf("Testing: NewFlagSetFromSchema(%+v); Expected: error; Got: %v", input, fs)
+		}
+	}
+	layout := &Schema{Optionals: []*FlagSchema{{Names: []string{"--opt"}, Type: "int", MinArgs: 1, MaxArgs: 1,
+		Constraints: map[string]string{layoutKey: "2006"}}}}
+	if _, err := NewFlagSetFromSchema(layout, make(map[string]interface{})); err == nil {
+		t.Errorf("Testing: NewFlagSetFromSchema() with layout for int; Expected: error; Got: no error")
+	}
+	// flags whose primary names only differ in the prefix would share an entry in dst
+	clashing := []*Schema{
+		{Positionals: []*FlagSchema{{Names: []string{"src"}, Type: "string", MinArgs: 1, MaxArgs: 1}},
+			Optionals: []*FlagSchema{{Names: []string{"--src"}, Type: "string", MinArgs: 1, MaxArgs: 1}}},
+		{Optionals: []*FlagSchema{{Names: []string{"-v"}, Type: "bool"}, {Names: []string{"--v"}, Type: "bool"}}},
+	}
+	for _, input := range clashing {
+		var target *DefinitionError
+		if _, err := NewFlagSetFromSchema(input, make(map[string]interface{})); !errors.As(err, &target) {
+			t.Errorf("Testing: NewFlagSetFromSchema() with clashing keys; Expected: error of type %T; Got: %v", target, err)
+		}
+	}
+	if _, err := NewFlagSetFromJSON(bytes.NewBufferString("{"), make(map[string]interface{})); err == nil {
+		t.Errorf("Testing: NewFlagSetFromJSON() with invalid JSON; Expected: error; Got: no error")
+	}
+}
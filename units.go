@@ -0,0 +1,286 @@
+package flagparse
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	unitsBytes string = "bytes"
+	unitsSI    string = "si"
+)
+
+type unit struct {
+	suffix string
+	mult   int64
+}
+
+// byteUnits are the suffixes accepted for byte sizes, IEC units first so that they are preferred
+// when formatting. Suffixes are matched case-insensitively.
+var byteUnits = []unit{
+	{"EiB", 1 << 60}, {"PiB", 1 << 50}, {"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10},
+	{"EB", 1e18}, {"PB", 1e15}, {"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3},
+	{"Ei", 1 << 60}, {"Pi", 1 << 50}, {"Ti", 1 << 40}, {"Gi", 1 << 30}, {"Mi", 1 << 20}, {"Ki", 1 << 10},
+	{"E", 1e18}, {"P", 1e15}, {"T", 1e12}, {"G", 1e9}, {"M", 1e6}, {"K", 1e3},
+	{"B", 1},
+}
+
+// siUnits are the suffixes accepted for SI quantities. Suffixes are matched case-sensitively except
+// for "k" which is accepted as "K" too.
+var siUnits = []unit{
+	{"E", 1e18}, {"P", 1e15}, {"T", 1e12}, {"G", 1e9}, {"M", 1e6}, {"k", 1e3}, {"K", 1e3},
+}
+
+// parseQuantity parses s, a number followed by an optional suffix from units, into an exact
+// rational number.
+func parseQuantity(s string, units string) (*big.Rat, error) {
+	// the suffix is the trailing run of letters, an exponent like "1e3" is part of the number
+	i := len(s)
+	for i > 0 && unicode.IsLetter(rune(s[i-1])) {
+		i--
+	}
+	num, suffix := s[:i], s[i:]
+	r, ok := new(big.Rat).SetString(num)
+	// SetString accepts fractions like "1/2" too
+	if !ok || strings.Contains(num, "/") {
+		return nil, fmt.Errorf("invalid number")
+	}
+	if suffix == "" {
+		return r, nil
+	}
+	for _, u := range unitTable(units) {
+		if suffix == u.suffix || (units == unitsBytes && strings.EqualFold(suffix, u.suffix)) {
+			return r.Mul(r, new(big.Rat).SetInt64(u.mult)), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown unit '%s'", suffix)
+}
+
+func unitTable(units string) []unit {
+	if units == unitsBytes {
+		return byteUnits
+	}
+	return siUnits
+}
+
+// unitsValue wraps a Value of an integer or float type, or a slice of these, so that arguments
+// may be given with a unit suffix like "10MiB" for bytes or "2k" for SI quantities. String returns
+// the value in the most readable unit which represents it exactly, see format.
+type unitsValue struct {
+	Value
+	value    reflect.Value // the addressable number or slice of numbers
+	units    string
+	typeName string // name of the number type for error messages
+}
+
+func newUnitsValue(val Value, units string) (*unitsValue, error) {
+	v := reflect.ValueOf(valueTarget(val))
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	typ := reflect.TypeOf(val.Get())
+	if v.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+	default:
+		return nil, fmt.Errorf("key '%s' is not supported for type '%T'", unitsKey, val.Get())
+	}
+	return &unitsValue{Value: val, value: v, units: units, typeName: typ.String()}, nil
+}
+
+func (uv *unitsValue) Set(values ...string) error {
+	if uv.value.Kind() != reflect.Slice {
+		if len(values) == 0 {
+			return nil
+		}
+		n := reflect.New(uv.value.Type()).Elem()
+		if err := uv.parse(values[0], n); err != nil {
+			return err
+		}
+		uv.value.Set(n)
+		return nil
+	}
+	list := reflect.MakeSlice(uv.value.Type(), len(values), len(values))
+	for i, val := range values {
+		if err := uv.parse(val, list.Index(i)); err != nil {
+			return err
+		}
+	}
+	uv.value.Set(list)
+	return nil
+}
+
+// parse sets n, a settable number, to the quantity s.
+func (uv *unitsValue) parse(s string, n reflect.Value) error {
+	r, err := parseQuantity(s, uv.units)
+	if err == nil {
+		err = setRat(n, r)
+	}
+	if err != nil {
+		return formatParseError(s, uv.typeName, err)
+	}
+	return nil
+}
+
+// setRat sets n, a settable number, to r. It returns error if r does not fit into n.
+func setRat(n reflect.Value, r *big.Rat) error {
+	switch n.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f, _ := r.Float64()
+		if n.OverflowFloat(f) {
+			return fmt.Errorf("value out of range")
+		}
+		n.SetFloat(f)
+		return nil
+	}
+	if !r.IsInt() {
+		return fmt.Errorf("not a whole number")
+	}
+	i := r.Num()
+	switch n.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !i.IsInt64() || n.OverflowInt(i.Int64()) {
+			return fmt.Errorf("value out of range")
+		}
+		n.SetInt(i.Int64())
+	default:
+		if !i.IsUint64() || n.OverflowUint(i.Uint64()) {
+			return fmt.Errorf("value out of range")
+		}
+		n.SetUint(i.Uint64())
+	}
+	return nil
+}
+
+func (uv *unitsValue) String() string {
+	if uv.value.Kind() != reflect.Slice {
+		return uv.format(uv.value)
+	}
+	elems := make([]string, uv.value.Len())
+	for i := range elems {
+		elems[i] = uv.format(uv.value.Index(i))
+	}
+	return "[" + strings.Join(elems, " ") + "]"
+}
+
+// format returns the number n in human form, for e.g. 1536 bytes as "1.5KiB", 1.5e9 bytes as
+// "1.5GB" and 512000 bytes as "512KB". Of the units which represent n exactly with at most two
+// decimals the one giving the shortest number is used, then the one with the largest prefix and
+// the fewest decimals, preferring SI over IEC units for the same prefix. Numbers too large for any
+// unit to be readable are shown in exponent form.
+func (uv *unitsValue) format(n reflect.Value) string {
+	r := new(big.Rat)
+	switch n.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := n.Float()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return fmt.Sprint(n.Interface())
+		}
+		if math.Abs(f) >= maxUnitsFloat {
+			return strconv.FormatFloat(f, 'g', -1, n.Type().Bits()) + uv.plainSuffix()
+		}
+		r.SetFloat64(f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		r.SetInt64(n.Int())
+	default:
+		r.SetInt(new(big.Int).SetUint64(n.Uint()))
+	}
+	parsed := reflect.New(n.Type()).Elem()
+	var best *unitCandidate
+	for _, u := range unitTable(uv.units) {
+		// bytes are shown with the "B" suffixes only
+		if uv.units == unitsBytes && !strings.HasSuffix(u.suffix, "B") {
+			continue
+		}
+		mult := new(big.Rat).SetInt64(u.mult)
+		if new(big.Rat).Abs(r).Cmp(mult) < 0 {
+			continue
+		}
+		num := trimFloatString(new(big.Rat).Quo(r, mult).FloatString(2))
+		// use the unit only if no precision is lost
+		if uv.parse(num+u.suffix, parsed) != nil || parsed.Interface() != n.Interface() {
+			continue
+		}
+		c := &unitCandidate{num: num, suffix: u.suffix}
+		if best == nil || c.better(best) {
+			best = c
+		}
+	}
+	if best != nil {
+		return best.num + best.suffix
+	}
+	s := trimFloatString(r.FloatString(6))
+	if uv.parse(s, parsed) != nil || parsed.Interface() != n.Interface() {
+		s = fmt.Sprint(n.Interface())
+	}
+	return s + uv.plainSuffix()
+}
+
+// unitCandidate is a number along with the unit suffix it is given in.
+type unitCandidate struct {
+	num    string
+	suffix string
+}
+
+// better reports whether c is more readable than other, see format.
+func (c *unitCandidate) better(other *unitCandidate) bool {
+	if len(c.num) != len(other.num) {
+		return len(c.num) < len(other.num)
+	}
+	if r1, r2 := unitRank(c.suffix), unitRank(other.suffix); r1 != r2 {
+		return r1 > r2
+	}
+	if d1, d2 := c.decimals(), other.decimals(); d1 != d2 {
+		return d1 < d2
+	}
+	return strings.Contains(other.suffix, "i") && !strings.Contains(c.suffix, "i")
+}
+
+// decimals returns the number of digits after the decimal point.
+func (c *unitCandidate) decimals() int {
+	if i := strings.Index(c.num, "."); i >= 0 {
+		return len(c.num) - i - 1
+	}
+	return 0
+}
+
+// maxUnitsFloat is the magnitude from which floats are shown in exponent form rather than with a
+// unit, since even the largest unit would need more than six digits.
+const maxUnitsFloat = 1e24
+
+// plainSuffix returns the suffix of a number without a unit.
+func (uv *unitsValue) plainSuffix() string {
+	if uv.units == unitsBytes {
+		return "B"
+	}
+	return ""
+}
+
+// unitRank returns the rank of the prefix of a unit's suffix, higher for larger prefixes. SI and
+// IEC units with the same prefix, like "KB" and "KiB", have the same rank.
+func unitRank(suffix string) int {
+	return strings.Index("KMGTPE", strings.ToUpper(suffix[:1])) + 1
+}
+
+// trimFloatString removes trailing zeros of the fractional part of s.
+func trimFloatString(s string) string {
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+func (uv *unitsValue) target() interface{} { return uv.value.Addr().Interface() }
+
+func (uv *unitsValue) constraints() map[string]string {
+	return map[string]string{unitsKey: uv.units}
+}
@@ -0,0 +1,195 @@
+package flagparse
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_parseQuantity(t *testing.T) {
+	data := []struct {
+		units    string
+		input    string
+		expected int64
+	}{
+		{unitsBytes, "0", 0},
+		{unitsBytes, "512", 512},
+		{unitsBytes, "512B", 512},
+		{unitsBytes, "512K", 512000},
+		{unitsBytes, "512KiB", 512 << 10},
+		{unitsBytes, "10MiB", 10 << 20},
+		{unitsBytes, "10mib", 10 << 20},
+		{unitsBytes, "1.5GB", 1500000000},
+		{unitsBytes, "1.5Gi", 3 << 29},
+		{unitsBytes, "2TB", 2e12},
+		{unitsBytes, "1e3KB", 1e6},
+		{unitsBytes, "-1KiB", -1024},
+		{unitsBytes, "7EiB", 7 << 60},
+		{unitsSI, "2k", 2000},
+		{unitsSI, "2K", 2000},
+		{unitsSI, "3M", 3e6},
+		{unitsSI, "0.5G", 5e8},
+		{unitsSI, "1E", 1e18},
+	}
+	for _, val := range data {
+		r, err := parseQuantity(val.input, val.units)
+		if err != nil {
+			t.Errorf("Testing: parseQuantity(%q, %s); Expected: no error; Got: %s", val.input, val.units, err)
+			continue
+		}
+		if !r.IsInt() || r.Num().Int64() != val.expected {
+			t.Errorf("Testing: parseQuantity(%q, %s); Expected: %d; Got: %s", val.input, val.units, val.expected, r)
+		}
+	}
+
+	invalid := []struct {
+		units string
+		input string
+	}{
+		{unitsBytes, ""},
+		{unitsBytes, "KiB"},
+		{unitsBytes, "10XB"},
+		{unitsBytes, "1/2KiB"},
+		{unitsBytes, "1 KiB"},
+		{unitsSI, "2Ki"},
+		{unitsSI, "3m"},
+		{unitsSI, "2kB"},
+	}
+	for _, val := range invalid {
+		if _, err := parseQuantity(val.input, val.units); err == nil {
+			t.Errorf("Testing: parseQuantity(%q, %s); Expected: error; Got: no error", val.input, val.units)
+		}
+	}
+}
+
+func TestUnitsType(t *testing.T) {
+	var testVar int64
+	testVal, err := newUnitsValue(newInt64Value(&testVar), unitsBytes)
+	if err != nil {
+		t.Fatalf("Expected: newUnitsValue() should succeed, Got: %s", err)
+	}
+	if testVal.String() != "0B" {
+		t.Errorf("Expected: %v, Got: %v", "0B", testVal.String())
+	}
+
+	data := []struct {
+		input    string
+		expected int64
+		str      string
+	}{
+		{"1536", 1536, "1.5KiB"},
+		{"1000", 1000, "1KB"},
+		{"1023", 1023, "1023B"},
+		{"1.25GiB", 5 << 28, "1.25GiB"},
+		{"10MB", 1e7, "10MB"},
+		{"-2KiB", -2048, "-2KiB"},
+		{"9223372036854775807", math.MaxInt64, "9223372036854775807B"},
+		// SI inputs are shown in SI units
+		{"1.5GB", 1.5e9, "1.5GB"},
+		{"512K", 512e3, "512KB"},
+		{"2.5KiB", 2560, "2.5KiB"},
+	}
+
+	// Test valid values
+	for _, val := range data {
+		if err := testVal.Set(val.input); err != nil {
+			t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, val.input)
+		}
+		if testVar != val.expected {
+			t.Errorf("Expected: %v, Got: %v", val.expected, testVar)
+		}
+		if testVal.Get() != val.expected {
+			t.Errorf("Expected: Get() should return the value %v; Got: %v", val.expected, testVal.Get())
+		}
+		if val.str != testVal.String() {
+			t.Errorf("Expected: %v, Got: %v", val.str, testVal.String())
+		}
+	}
+
+	// Test invalid values
+	for _, input := range []string{"hello", "1.5B", "8EiB", "10XB"} {
+		if err := testVal.Set(input); err == nil {
+			t.Errorf("Expected: error, Got: no error for input \"%s\"", input)
+		}
+	}
+	err = testVal.Set("8EiB")
+	expected := "cannot parse '8EiB' as type 'int64': value out of range"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected: error %q, Got: %v", expected, err)
+	}
+
+	// Test unsigned and float types
+	var u uint64
+	testVal, _ = newUnitsValue(newUint64Value(&u), unitsBytes)
+	if err := testVal.Set("16EiB"); err == nil {
+		t.Errorf("Expected: error, Got: no error for input \"%s\"", "16EiB")
+	}
+	if err := testVal.Set("15EiB"); err != nil || u != 15<<60 || testVal.String() != "15EiB" {
+		t.Errorf("Expected: %v, Got: %v, %q, error %v", uint64(15<<60), u, testVal.String(), err)
+	}
+	if err := testVal.Set("-1"); err == nil {
+		t.Errorf("Expected: error, Got: no error for input \"%s\"", "-1")
+	}
+
+	var f float64
+	testVal, _ = newUnitsValue(newFloat64Value(&f), unitsSI)
+	for _, val := range []struct {
+		input    string
+		expected float64
+		str      string
+	}{{"2k", 2000, "2k"}, {"3.5M", 3.5e6, "3.5M"}, {"0.1", 0.1, "0.1"}, {"1234", 1234, "1234"},
+		{"1500k", 1.5e6, "1.5M"}, {"250000", 250e3, "250k"}, {"1e300", 1e300, "1e+300"}} {
+		if err := testVal.Set(val.input); err != nil || f != val.expected {
+			t.Errorf("Expected: %v for input %q, Got: %v, error %v", val.expected, val.input, f, err)
+		}
+		if val.str != testVal.String() {
+			t.Errorf("Expected: %v, Got: %v", val.str, testVal.String())
+		}
+	}
+
+	// huge floats are shown in exponent form rather than with hundreds of digits
+	testVal, _ = newUnitsValue(newFloat64Value(&f), unitsBytes)
+	if err := testVal.Set("1e300"); err != nil || testVal.String() != "1e+300B" {
+		t.Errorf("Expected: %q, Got: %q, error %v", "1e+300B", testVal.String(), err)
+	}
+
+	// Test unsupported types
+	if _, err := newUnitsValue(newStringValue(new(string)), unitsSI); err == nil {
+		t.Errorf("Expected: error for units of string, Got: no error")
+	}
+}
+
+func TestUnitsListType(t *testing.T) {
+	var testVar []uint64
+	testVal, err := newUnitsValue(newUint64ListValue(&testVar), unitsBytes)
+	if err != nil {
+		t.Fatalf("Expected: newUnitsValue() should succeed, Got: %s", err)
+	}
+	data := struct {
+		input    []string
+		expected []uint64
+	}{
+		input:    []string{"1KiB", "2MB", "3"},
+		expected: []uint64{1024, 2e6, 3},
+	}
+
+	// Test valid values
+	if err := testVal.Set(data.input...); err != nil {
+		t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, data.input)
+	}
+	if !reflect.DeepEqual(data.expected, testVar) {
+		t.Errorf("Expected: %v, Got: %v", data.expected, testVar)
+	}
+	if !reflect.DeepEqual(testVal.Get(), testVar) {
+		t.Errorf("Expected: Get() should return the value %v; Got: %v", testVar, testVal.Get())
+	}
+	if testVal.String() != "[1KiB 2MB 3B]" {
+		t.Errorf("Expected: %v, Got: %v", "[1KiB 2MB 3B]", testVal.String())
+	}
+
+	// Test invalid values
+	input := []string{"1KiB", "2XB"}
+	if err := testVal.Set(input...); err == nil {
+		t.Errorf("Expected: error, Got: no error for input \"%s\"", input)
+	}
+}
@@ -1,8 +1,18 @@
 package flagparse
 
 import (
+	"encoding"
+	"errors"
+	stdflag "flag"
 	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
+	"unicode"
 )
 
 func formatParseError(val string, typeName string, err error) error {
@@ -38,8 +48,119 @@ type Value interface {
 	String() string
 }
 
+// targetValue is implemented by Values which keep the address of the underlying variable in a
+// field instead of being a named type of the variable itself. target returns that address.
+type targetValue interface {
+	target() interface{}
+}
+
+// valueTarget returns the address of the variable underlying val.
+func valueTarget(val Value) interface{} {
+	if tv, ok := val.(targetValue); ok {
+		return tv.target()
+	}
+	return val
+}
+
+// layoutValue is implemented by Values whose arguments are parsed as per a layout which can be
+// specified using the layout key of the struct tag.
+type layoutValue interface {
+	setLayout(layout string)
+}
+
+// dupKeysValue is implemented by Values of maps which can reject duplicate keys, as specified using
+// the dupkeys key of the struct tag.
+type dupKeysValue interface {
+	setRejectDupKeys(reject bool)
+}
+
+// defPortValue is implemented by Values of network endpoints which can fall back to a default port,
+// as specified using the defport key of the struct tag.
+type defPortValue interface {
+	setDefPort(port string)
+}
+
+// schemesValue is implemented by Values of URLs which only accept certain schemes, as specified
+// using the schemes key of the struct tag.
+type schemesValue interface {
+	setSchemes(schemes []string)
+}
+
+// accumValue is implemented by Values which collect the arguments of all occurrences of their flag
+// during a parse instead of only the last one. If accumulates returns true then Set is called with
+// the arguments of the current and all previous occurrences.
+type accumValue interface {
+	accumulates() bool
+}
+
+// applyValueKeys configures value as per those keys in keyValues which apply to the Value rather
+// than the flag and returns the Value to use, which may wrap value. It returns error if the type of
+// value does not support such a key.
+func applyValueKeys(value Value, keyValues map[string]string) (Value, error) {
+	// keys of pointer flags apply to the Value of the pointed to type
+	if pv, ok := value.(*ptrValue); ok {
+		elem, err := applyValueKeys(pv.elem, keyValues)
+		if err != nil {
+			return nil, err
+		}
+		pv.elem = elem
+		pv.keyValues = keyValues
+		return pv, nil
+	}
+	// keys of slice and array flags apply to the Value of each element
+	if sv, ok := value.(*sliceValue); ok {
+		elem := reflect.New(sv.value.Type().Elem())
+		// element type has been verified by newSliceValue
+		elemVal, _ := newValue(elem.Interface())
+		if _, err := applyValueKeys(elemVal, keyValues); err != nil {
+			return nil, err
+		}
+		sv.keyValues = keyValues
+		return sv, nil
+	}
+	if layout, ok := keyValues[layoutKey]; ok {
+		lv, ok := value.(layoutValue)
+		if !ok {
+			return nil, fmt.Errorf("key '%s' is not supported for type '%T'", layoutKey, value.Get())
+		}
+		lv.setLayout(layout)
+	}
+	if dupKeys, ok := keyValues[dupKeysKey]; ok {
+		dv, ok := value.(dupKeysValue)
+		if !ok {
+			return nil, fmt.Errorf("key '%s' is not supported for type '%T'", dupKeysKey, value.Get())
+		}
+		dv.setRejectDupKeys(dupKeys == "error")
+	}
+	if defPort, ok := keyValues[defPortKey]; ok {
+		dv, ok := value.(defPortValue)
+		if !ok {
+			return nil, fmt.Errorf("key '%s' is not supported for type '%T'", defPortKey, value.Get())
+		}
+		dv.setDefPort(defPort)
+	}
+	if schemes, ok := keyValues[schemesKey]; ok {
+		sv, ok := value.(schemesValue)
+		if !ok {
+			return nil, fmt.Errorf("key '%s' is not supported for type '%T'", schemesKey, value.Get())
+		}
+		sv.setSchemes(strings.Split(schemes, optNameSep))
+	}
+	if units, ok := keyValues[unitsKey]; ok {
+		return newUnitsValue(value, units)
+	}
+	_, isPath := keyValues[pathKey]
+	if abs, ok := keyValues[absKey]; isPath || ok {
+		return newPathValue(value, PathCheck(keyValues[pathKey]), abs == "true")
+	}
+	return value, nil
+}
+
 // newValue takes address of a variable and returns a compatible Value type so that it can be used
-// with this package. It returns error if there is no compatible type for the variable.
+// with this package. Besides the built-in types, addresses implementing the standard library's
+// flag.Value or encoding.TextUnmarshaler interfaces are adapted to Value, as are slices, arrays
+// and maps with string keys of any supported element type and pointers to any supported type. It
+// returns error if there is no compatible type for the variable.
 func newValue(v interface{}) (Value, error) {
 	switch addr := v.(type) {
 	case Value: // the type itself implements Value interface hence simply return addr
@@ -56,11 +177,87 @@ func newValue(v interface{}) (Value, error) {
 		return newIntValue(addr), nil
 	case *[]int:
 		return newIntListValue(addr), nil
+	case *int8:
+		return newInt8Value(addr), nil
+	case *[]int8:
+		return newInt8ListValue(addr), nil
+	case *int16:
+		return newInt16Value(addr), nil
+	case *[]int16:
+		return newInt16ListValue(addr), nil
+	case *int32:
+		return newInt32Value(addr), nil
+	case *[]int32:
+		return newInt32ListValue(addr), nil
+	case *int64:
+		return newInt64Value(addr), nil
+	case *[]int64:
+		return newInt64ListValue(addr), nil
+	case *uint:
+		return newUintValue(addr), nil
+	case *[]uint:
+		return newUintListValue(addr), nil
+	case *uint8:
+		return newUint8Value(addr), nil
+	case *[]uint8:
+		return newUint8ListValue(addr), nil
+	case *uint16:
+		return newUint16Value(addr), nil
+	case *[]uint16:
+		return newUint16ListValue(addr), nil
+	case *uint32:
+		return newUint32Value(addr), nil
+	case *[]uint32:
+		return newUint32ListValue(addr), nil
+	case *uint64:
+		return newUint64Value(addr), nil
+	case *[]uint64:
+		return newUint64ListValue(addr), nil
+	case *uintptr:
+		return newUintptrValue(addr), nil
+	case *[]uintptr:
+		return newUintptrListValue(addr), nil
 	case *float64:
 		return newFloat64Value(addr), nil
 	case *[]float64:
 		return newFloat64ListValue(addr), nil
+	case *net.IP:
+		return newIPValue(addr), nil
+	case *net.IPNet:
+		return newIPNetValue(addr), nil
+	case *HostPort:
+		return newHostPortValue(addr), nil
+	case *url.URL:
+		return newURLValue(addr), nil
+	case *time.Duration:
+		return newDurationValue(addr), nil
+	case *[]time.Duration:
+		return newDurationListValue(addr), nil
+	case *time.Time:
+		return newTimeValue(addr), nil
+	case *[]time.Time:
+		return newTimeListValue(addr), nil
+	case stdflag.Value:
+		return newStdFlagValue(addr), nil
+	case encoding.TextUnmarshaler:
+		return newTextValue(addr), nil
 	default:
+		if v := reflect.ValueOf(addr); v.Kind() == reflect.Ptr && !v.IsNil() {
+			switch v.Elem().Kind() {
+			case reflect.Slice, reflect.Array:
+				if sv, err := newSliceValue(v.Elem()); err == nil {
+					return sv, nil
+				}
+			case reflect.Map:
+				if mv, err := newMapValue(v.Elem()); err == nil {
+					return mv, nil
+				}
+			case reflect.Ptr:
+				if pv, err := newPtrValue(v.Elem()); err == nil {
+					return pv, nil
+				}
+			}
+		}
 		return nil, fmt.Errorf("type '%T' does not implement the Value interface", addr)
 	}
 }
@@ -198,49 +395,1188 @@ func (il *intListValue) Get() interface{} { return []int(*il) }
 
 func (il *intListValue) String() string { return fmt.Sprint(*il) }
 
-// float64Value wraps the built-in float64 type and implements the Value interface
-type float64Value float64
+// int8Value wraps the built-in int8 type and implements the Value interface
+type int8Value int8
 
-func newFloat64Value(p *float64) *float64Value {
-	return (*float64Value)(p)
+func newInt8Value(p *int8) *int8Value {
+	return (*int8Value)(p)
 }
 
-func (f *float64Value) Set(values ...string) error {
+func (i *int8Value) Set(values ...string) error {
 	if len(values) == 0 {
 		return nil
 	}
-	v, err := strconv.ParseFloat(values[0], 64)
+	v, err := strconv.ParseInt(values[0], 0, 8)
 	if err != nil {
-		return formatParseError(values[0], fmt.Sprintf("%T", float64(1)), err)
+		return formatParseError(values[0], fmt.Sprintf("%T", int8(1)), err)
 	}
-	*f = float64Value(v)
+	*i = int8Value(v)
 	return nil
 }
 
-func (f *float64Value) Get() interface{} { return float64(*f) }
+func (i *int8Value) Get() interface{} { return int8(*i) }
 
-func (f *float64Value) String() string { return strconv.FormatFloat(float64(*f), 'g', -1, 64) }
+func (i *int8Value) String() string { return strconv.FormatInt(int64(*i), 10) }
 
-// float64ListValue wraps the built-in []float64 type and implements the Value interface
-type float64ListValue []float64
+// int8ListValue wraps the built-in []int8 type and implements the Value interface
+type int8ListValue []int8
 
-func newFloat64ListValue(p *[]float64) *float64ListValue {
-	return (*float64ListValue)(p)
+func newInt8ListValue(p *[]int8) *int8ListValue {
+	return (*int8ListValue)(p)
 }
 
-func (fl *float64ListValue) Set(values ...string) error {
-	*fl = make([]float64, len(values))
+func (il *int8ListValue) Set(values ...string) error {
+	*il = make([]int8, len(values))
+	for i, val := range values {
+		n, err := strconv.ParseInt(val, 0, 8)
+		if err != nil {
+			return formatParseError(val, fmt.Sprintf("%T", int8(1)), err)
+		}
+		(*il)[i] = int8(n)
+	}
+	return nil
+}
+
+func (il *int8ListValue) Get() interface{} { return []int8(*il) }
+
+func (il *int8ListValue) String() string { return fmt.Sprint(*il) }
+
+// int16Value wraps the built-in int16 type and implements the Value interface
+type int16Value int16
+
+func newInt16Value(p *int16) *int16Value {
+	return (*int16Value)(p)
+}
+
+func (i *int16Value) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := strconv.ParseInt(values[0], 0, 16)
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", int16(1)), err)
+	}
+	*i = int16Value(v)
+	return nil
+}
+
+func (i *int16Value) Get() interface{} { return int16(*i) }
+
+func (i *int16Value) String() string { return strconv.FormatInt(int64(*i), 10) }
+
+// int16ListValue wraps the built-in []int16 type and implements the Value interface
+type int16ListValue []int16
+
+func newInt16ListValue(p *[]int16) *int16ListValue {
+	return (*int16ListValue)(p)
+}
 
+func (il *int16ListValue) Set(values ...string) error {
+	*il = make([]int16, len(values))
 	for i, val := range values {
-		f, err := strconv.ParseFloat(val, 64)
+		n, err := strconv.ParseInt(val, 0, 16)
 		if err != nil {
-			return formatParseError(val, fmt.Sprintf("%T", float64(1)), err)
+			return formatParseError(val, fmt.Sprintf("%T", int16(1)), err)
 		}
-		(*fl)[i] = f
+		(*il)[i] = int16(n)
 	}
 	return nil
 }
 
-func (fl *float64ListValue) Get() interface{} { return []float64(*fl) }
+func (il *int16ListValue) Get() interface{} { return []int16(*il) }
 
-func (fl *float64ListValue) String() string { return fmt.Sprint(*fl) }
+func (il *int16ListValue) String() string { return fmt.Sprint(*il) }
+
+// int32Value wraps the built-in int32 type and implements the Value interface
+type int32Value int32
+
+func newInt32Value(p *int32) *int32Value {
+	return (*int32Value)(p)
+}
+
+func (i *int32Value) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := strconv.ParseInt(values[0], 0, 32)
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", int32(1)), err)
+	}
+	*i = int32Value(v)
+	return nil
+}
+
+func (i *int32Value) Get() interface{} { return int32(*i) }
+
+func (i *int32Value) String() string { return strconv.FormatInt(int64(*i), 10) }
+
+// int32ListValue wraps the built-in []int32 type and implements the Value interface
+type int32ListValue []int32
+
+func newInt32ListValue(p *[]int32) *int32ListValue {
+	return (*int32ListValue)(p)
+}
+
+func (il *int32ListValue) Set(values ...string) error {
+	*il = make([]int32, len(values))
+	for i, val := range values {
+		n, err := strconv.ParseInt(val, 0, 32)
+		if err != nil {
+			return formatParseError(val, fmt.Sprintf("%T", int32(1)), err)
+		}
+		(*il)[i] = int32(n)
+	}
+	return nil
+}
+
+func (il *int32ListValue) Get() interface{} { return []int32(*il) }
+
+func (il *int32ListValue) String() string { return fmt.Sprint(*il) }
+
+// int64Value wraps the built-in int64 type and implements the Value interface
+type int64Value int64
+
+func newInt64Value(p *int64) *int64Value {
+	return (*int64Value)(p)
+}
+
+func (i *int64Value) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := strconv.ParseInt(values[0], 0, 64)
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", int64(1)), err)
+	}
+	*i = int64Value(v)
+	return nil
+}
+
+func (i *int64Value) Get() interface{} { return int64(*i) }
+
+func (i *int64Value) String() string { return strconv.FormatInt(int64(*i), 10) }
+
+// int64ListValue wraps the built-in []int64 type and implements the Value interface
+type int64ListValue []int64
+
+func newInt64ListValue(p *[]int64) *int64ListValue {
+	return (*int64ListValue)(p)
+}
+
+func (il *int64ListValue) Set(values ...string) error {
+	*il = make([]int64, len(values))
+	for i, val := range values {
+		n, err := strconv.ParseInt(val, 0, 64)
+		if err != nil {
+			return formatParseError(val, fmt.Sprintf("%T", int64(1)), err)
+		}
+		(*il)[i] = int64(n)
+	}
+	return nil
+}
+
+func (il *int64ListValue) Get() interface{} { return []int64(*il) }
+
+func (il *int64ListValue) String() string { return fmt.Sprint(*il) }
+
+// uintValue wraps the built-in uint type and implements the Value interface
+type uintValue uint
+
+func newUintValue(p *uint) *uintValue {
+	return (*uintValue)(p)
+}
+
+func (u *uintValue) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := strconv.ParseUint(values[0], 0, strconv.IntSize)
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", uint(1)), err)
+	}
+	*u = uintValue(v)
+	return nil
+}
+
+func (u *uintValue) Get() interface{} { return uint(*u) }
+
+func (u *uintValue) String() string { return strconv.FormatUint(uint64(*u), 10) }
+
+// uintListValue wraps the built-in []uint type and implements the Value interface
+type uintListValue []uint
+
+func newUintListValue(p *[]uint) *uintListValue {
+	return (*uintListValue)(p)
+}
+
+func (ul *uintListValue) Set(values ...string) error {
+	*ul = make([]uint, len(values))
+	for i, val := range values {
+		n, err := strconv.ParseUint(val, 0, strconv.IntSize)
+		if err != nil {
+			return formatParseError(val, fmt.Sprintf("%T", uint(1)), err)
+		}
+		(*ul)[i] = uint(n)
+	}
+	return nil
+}
+
+func (ul *uintListValue) Get() interface{} { return []uint(*ul) }
+
+func (ul *uintListValue) String() string { return fmt.Sprint(*ul) }
+
+// uint8Value wraps the built-in uint8 type and implements the Value interface
+type uint8Value uint8
+
+func newUint8Value(p *uint8) *uint8Value {
+	return (*uint8Value)(p)
+}
+
+func (u *uint8Value) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := strconv.ParseUint(values[0], 0, 8)
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", uint8(1)), err)
+	}
+	*u = uint8Value(v)
+	return nil
+}
+
+func (u *uint8Value) Get() interface{} { return uint8(*u) }
+
+func (u *uint8Value) String() string { return strconv.FormatUint(uint64(*u), 10) }
+
+// uint8ListValue wraps the built-in []uint8 type and implements the Value interface
+type uint8ListValue []uint8
+
+func newUint8ListValue(p *[]uint8) *uint8ListValue {
+	return (*uint8ListValue)(p)
+}
+
+func (ul *uint8ListValue) Set(values ...string) error {
+	*ul = make([]uint8, len(values))
+	for i, val := range values {
+		n, err := strconv.ParseUint(val, 0, 8)
+		if err != nil {
+			return formatParseError(val, fmt.Sprintf("%T", uint8(1)), err)
+		}
+		(*ul)[i] = uint8(n)
+	}
+	return nil
+}
+
+func (ul *uint8ListValue) Get() interface{} { return []uint8(*ul) }
+
+func (ul *uint8ListValue) String() string { return fmt.Sprint(*ul) }
+
+// uint16Value wraps the built-in uint16 type and implements the Value interface
+type uint16Value uint16
+
+func newUint16Value(p *uint16) *uint16Value {
+	return (*uint16Value)(p)
+}
+
+func (u *uint16Value) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := strconv.ParseUint(values[0], 0, 16)
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", uint16(1)), err)
+	}
+	*u = uint16Value(v)
+	return nil
+}
+
+func (u *uint16Value) Get() interface{} { return uint16(*u) }
+
+func (u *uint16Value) String() string { return strconv.FormatUint(uint64(*u), 10) }
+
+// uint16ListValue wraps the built-in []uint16 type and implements the Value interface
+type uint16ListValue []uint16
+
+func newUint16ListValue(p *[]uint16) *uint16ListValue {
+	return (*uint16ListValue)(p)
+}
+
+func (ul *uint16ListValue) Set(values ...string) error {
+	*ul = make([]uint16, len(values))
+	for i, val := range values {
+		n, err := strconv.ParseUint(val, 0, 16)
+		if err != nil {
+			return formatParseError(val, fmt.Sprintf("%T", uint16(1)), err)
+		}
+		(*ul)[i] = uint16(n)
+	}
+	return nil
+}
+
+func (ul *uint16ListValue) Get() interface{} { return []uint16(*ul) }
+
+func (ul *uint16ListValue) String() string { return fmt.Sprint(*ul) }
+
+// uint32Value wraps the built-in uint32 type and implements the Value interface
+type uint32Value uint32
+
+func newUint32Value(p *uint32) *uint32Value {
+	return (*uint32Value)(p)
+}
+
+func (u *uint32Value) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := strconv.ParseUint(values[0], 0, 32)
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", uint32(1)), err)
+	}
+	*u = uint32Value(v)
+	return nil
+}
+
+func (u *uint32Value) Get() interface{} { return uint32(*u) }
+
+func (u *uint32Value) String() string { return strconv.FormatUint(uint64(*u), 10) }
+
+// uint32ListValue wraps the built-in []uint32 type and implements the Value interface
+type uint32ListValue []uint32
+
+func newUint32ListValue(p *[]uint32) *uint32ListValue {
+	return (*uint32ListValue)(p)
+}
+
+func (ul *uint32ListValue) Set(values ...string) error {
+	*ul = make([]uint32, len(values))
+	for i, val := range values {
+		n, err := strconv.ParseUint(val, 0, 32)
+		if err != nil {
+			return formatParseError(val, fmt.Sprintf("%T", uint32(1)), err)
+		}
+		(*ul)[i] = uint32(n)
+	}
+	return nil
+}
+
+func (ul *uint32ListValue) Get() interface{} { return []uint32(*ul) }
+
+func (ul *uint32ListValue) String() string { return fmt.Sprint(*ul) }
+
+// uint64Value wraps the built-in uint64 type and implements the Value interface
+type uint64Value uint64
+
+func newUint64Value(p *uint64) *uint64Value {
+	return (*uint64Value)(p)
+}
+
+func (u *uint64Value) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := strconv.ParseUint(values[0], 0, 64)
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", uint64(1)), err)
+	}
+	*u = uint64Value(v)
+	return nil
+}
+
+func (u *uint64Value) Get() interface{} { return uint64(*u) }
+
+func (u *uint64Value) String() string { return strconv.FormatUint(uint64(*u), 10) }
+
+// uint64ListValue wraps the built-in []uint64 type and implements the Value interface
+type uint64ListValue []uint64
+
+func newUint64ListValue(p *[]uint64) *uint64ListValue {
+	return (*uint64ListValue)(p)
+}
+
+func (ul *uint64ListValue) Set(values ...string) error {
+	*ul = make([]uint64, len(values))
+	for i, val := range values {
+		n, err := strconv.ParseUint(val, 0, 64)
+		if err != nil {
+			return formatParseError(val, fmt.Sprintf("%T", uint64(1)), err)
+		}
+		(*ul)[i] = uint64(n)
+	}
+	return nil
+}
+
+func (ul *uint64ListValue) Get() interface{} { return []uint64(*ul) }
+
+func (ul *uint64ListValue) String() string { return fmt.Sprint(*ul) }
+
+// uintptrValue wraps the built-in uintptr type and implements the Value interface
+type uintptrValue uintptr
+
+func newUintptrValue(p *uintptr) *uintptrValue {
+	return (*uintptrValue)(p)
+}
+
+func (u *uintptrValue) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := strconv.ParseUint(values[0], 0, strconv.IntSize)
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", uintptr(1)), err)
+	}
+	*u = uintptrValue(v)
+	return nil
+}
+
+func (u *uintptrValue) Get() interface{} { return uintptr(*u) }
+
+func (u *uintptrValue) String() string { return strconv.FormatUint(uint64(*u), 10) }
+
+// uintptrListValue wraps the built-in []uintptr type and implements the Value interface
+type uintptrListValue []uintptr
+
+func newUintptrListValue(p *[]uintptr) *uintptrListValue {
+	return (*uintptrListValue)(p)
+}
+
+func (ul *uintptrListValue) Set(values ...string) error {
+	*ul = make([]uintptr, len(values))
+	for i, val := range values {
+		n, err := strconv.ParseUint(val, 0, strconv.IntSize)
+		if err != nil {
+			return formatParseError(val, fmt.Sprintf("%T", uintptr(1)), err)
+		}
+		(*ul)[i] = uintptr(n)
+	}
+	return nil
+}
+
+func (ul *uintptrListValue) Get() interface{} { return []uintptr(*ul) }
+
+func (ul *uintptrListValue) String() string { return fmt.Sprint(*ul) }
+
+// float64Value wraps the built-in float64 type and implements the Value interface
+type float64Value float64
+
+func newFloat64Value(p *float64) *float64Value {
+	return (*float64Value)(p)
+}
+
+func (f *float64Value) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := strconv.ParseFloat(values[0], 64)
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", float64(1)), err)
+	}
+	*f = float64Value(v)
+	return nil
+}
+
+func (f *float64Value) Get() interface{} { return float64(*f) }
+
+func (f *float64Value) String() string { return strconv.FormatFloat(float64(*f), 'g', -1, 64) }
+
+// float64ListValue wraps the built-in []float64 type and implements the Value interface
+type float64ListValue []float64
+
+func newFloat64ListValue(p *[]float64) *float64ListValue {
+	return (*float64ListValue)(p)
+}
+
+func (fl *float64ListValue) Set(values ...string) error {
+	*fl = make([]float64, len(values))
+
+	for i, val := range values {
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return formatParseError(val, fmt.Sprintf("%T", float64(1)), err)
+		}
+		(*fl)[i] = f
+	}
+	return nil
+}
+
+func (fl *float64ListValue) Get() interface{} { return []float64(*fl) }
+
+func (fl *float64ListValue) String() string { return fmt.Sprint(*fl) }
+
+// durationValue wraps the time.Duration type and implements the Value interface
+type durationValue time.Duration
+
+func newDurationValue(p *time.Duration) *durationValue {
+	return (*durationValue)(p)
+}
+
+func (d *durationValue) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := time.ParseDuration(values[0])
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", time.Duration(1)), err)
+	}
+	*d = durationValue(v)
+	return nil
+}
+
+func (d *durationValue) Get() interface{} { return time.Duration(*d) }
+
+func (d *durationValue) String() string { return time.Duration(*d).String() }
+
+// durationListValue wraps the []time.Duration type and implements the Value interface
+type durationListValue []time.Duration
+
+func newDurationListValue(p *[]time.Duration) *durationListValue {
+	return (*durationListValue)(p)
+}
+
+func (dl *durationListValue) Set(values ...string) error {
+	*dl = make([]time.Duration, len(values))
+	for i, val := range values {
+		v, err := time.ParseDuration(val)
+		if err != nil {
+			return formatParseError(val, fmt.Sprintf("%T", time.Duration(1)), err)
+		}
+		(*dl)[i] = v
+	}
+	return nil
+}
+
+func (dl *durationListValue) Get() interface{} { return []time.Duration(*dl) }
+
+func (dl *durationListValue) String() string { return fmt.Sprint(*dl) }
+
+// timeValue wraps the time.Time type and implements the Value interface. Arguments are parsed as
+// per layout which is time.RFC3339 by default.
+type timeValue struct {
+	value  *time.Time
+	layout string
+}
+
+func newTimeValue(p *time.Time) *timeValue {
+	return &timeValue{value: p, layout: time.RFC3339}
+}
+
+func (t *timeValue) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := time.Parse(t.layout, values[0])
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", time.Time{}), err)
+	}
+	*t.value = v
+	return nil
+}
+
+func (t *timeValue) Get() interface{} { return *t.value }
+
+// String returns an empty string for the zero time so that it is not shown as a default value.
+func (t *timeValue) String() string { return formatTime(*t.value, t.layout) }
+
+func (t *timeValue) target() interface{} { return t.value }
+
+func (t *timeValue) setLayout(layout string) { t.layout = layout }
+
+func (t *timeValue) constraints() map[string]string { return layoutConstraints(t.layout) }
+
+// timeListValue wraps the []time.Time type and implements the Value interface. Arguments are parsed
+// as per layout which is time.RFC3339 by default.
+type timeListValue struct {
+	value  *[]time.Time
+	layout string
+}
+
+func newTimeListValue(p *[]time.Time) *timeListValue {
+	return &timeListValue{value: p, layout: time.RFC3339}
+}
+
+func (tl *timeListValue) Set(values ...string) error {
+	*tl.value = make([]time.Time, len(values))
+	for i, val := range values {
+		v, err := time.Parse(tl.layout, val)
+		if err != nil {
+			return formatParseError(val, fmt.Sprintf("%T", time.Time{}), err)
+		}
+		(*tl.value)[i] = v
+	}
+	return nil
+}
+
+func (tl *timeListValue) Get() interface{} { return *tl.value }
+
+func (tl *timeListValue) String() string {
+	elems := make([]string, len(*tl.value))
+	for i, v := range *tl.value {
+		elems[i] = formatTime(v, tl.layout)
+	}
+	return "[" + strings.Join(elems, " ") + "]"
+}
+
+func (tl *timeListValue) target() interface{} { return tl.value }
+
+func (tl *timeListValue) setLayout(layout string) { tl.layout = layout }
+
+func (tl *timeListValue) constraints() map[string]string { return layoutConstraints(tl.layout) }
+
+func formatTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// layoutConstraints returns the schema constraints for a time layout, the default layout is left out.
+func layoutConstraints(layout string) map[string]string {
+	if layout == time.RFC3339 {
+		return nil
+	}
+	return map[string]string{layoutKey: layout}
+}
+
+// ipValue wraps the net.IP type and implements the Value interface
+type ipValue net.IP
+
+func newIPValue(p *net.IP) *ipValue {
+	return (*ipValue)(p)
+}
+
+func (ip *ipValue) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v := net.ParseIP(values[0])
+	if v == nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", net.IP{}), fmt.Errorf("invalid IP address"))
+	}
+	*ip = ipValue(v)
+	return nil
+}
+
+func (ip *ipValue) Get() interface{} { return net.IP(*ip) }
+
+func (ip *ipValue) String() string {
+	if len(*ip) == 0 {
+		return ""
+	}
+	return net.IP(*ip).String()
+}
+
+// ipNetValue wraps the net.IPNet type and implements the Value interface. Arguments are given in
+// CIDR notation like "192.168.0.0/16".
+type ipNetValue net.IPNet
+
+func newIPNetValue(p *net.IPNet) *ipNetValue {
+	return (*ipNetValue)(p)
+}
+
+func (n *ipNetValue) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	_, v, err := net.ParseCIDR(values[0])
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", net.IPNet{}), fmt.Errorf("invalid CIDR address"))
+	}
+	*n = ipNetValue(*v)
+	return nil
+}
+
+func (n *ipNetValue) Get() interface{} { return net.IPNet(*n) }
+
+func (n *ipNetValue) String() string {
+	if len(n.IP) == 0 {
+		return ""
+	}
+	return (*net.IPNet)(n).String()
+}
+
+// HostPort is a network endpoint of the form "host:port" as accepted by net.Dial.
+type HostPort struct {
+	Host string
+	Port string
+}
+
+// String returns the endpoint as "host:port", IPv6 hosts are enclosed in brackets. It returns an
+// empty string for the zero HostPort.
+func (hp HostPort) String() string {
+	if hp == (HostPort{}) {
+		return ""
+	}
+	return net.JoinHostPort(hp.Host, hp.Port)
+}
+
+// hostPortValue wraps the HostPort type and implements the Value interface. If a default port is
+// set then arguments may omit the port.
+type hostPortValue struct {
+	value   *HostPort
+	defPort string
+}
+
+func newHostPortValue(p *HostPort) *hostPortValue {
+	return &hostPortValue{value: p}
+}
+
+func (hp *hostPortValue) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	host, port, err := hp.split(values[0])
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", HostPort{}), err)
+	}
+	*hp.value = HostPort{Host: host, Port: port}
+	return nil
+}
+
+// split splits s into host and port. The default port is used if s has no port, or is a bare IPv6
+// address, only.
+func (hp *hostPortValue) split(s string) (string, string, error) {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil && hp.defPort != "" {
+		if ae, ok := err.(*net.AddrError); ok && ae.Err == "missing port in address" {
+			host, err = s, nil
+			if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+				host = s[1 : len(s)-1]
+			}
+		} else if isIPv6(s) {
+			host, err = s, nil
+		}
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if port == "" {
+		if hp.defPort == "" {
+			return "", "", fmt.Errorf("missing port")
+		}
+		port = hp.defPort
+	}
+	if !validHostPortPart(host) {
+		return "", "", fmt.Errorf("invalid host '%s'", host)
+	}
+	if !validHostPortPart(port) {
+		return "", "", fmt.Errorf("invalid port '%s'", port)
+	}
+	return host, port, nil
+}
+
+// isIPv6 reports whether s is an IPv6 address, optionally with a zone like "fe80::1%eth0".
+func isIPv6(s string) bool {
+	if i := strings.LastIndex(s, "%"); i > 0 {
+		s = s[:i]
+	}
+	return strings.Contains(s, ":") && net.ParseIP(s) != nil
+}
+
+// validHostPortPart reports whether s can be the host or port of a HostPort: an IP address, a host
+// or service name or empty.
+func validHostPortPart(s string) bool {
+	if isIPv6(s) {
+		return true
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-._", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (hp *hostPortValue) Get() interface{} { return *hp.value }
+
+func (hp *hostPortValue) String() string { return hp.value.String() }
+
+func (hp *hostPortValue) target() interface{} { return hp.value }
+
+func (hp *hostPortValue) setDefPort(port string) { hp.defPort = port }
+
+func (hp *hostPortValue) constraints() map[string]string {
+	if hp.defPort == "" {
+		return nil
+	}
+	return map[string]string{defPortKey: hp.defPort}
+}
+
+// urlValue wraps the url.URL type and implements the Value interface. If schemes are set then only
+// URLs with one of them are accepted.
+type urlValue struct {
+	value   *url.URL
+	schemes []string
+}
+
+func newURLValue(p *url.URL) *urlValue {
+	return &urlValue{value: p}
+}
+
+func (u *urlValue) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := url.Parse(values[0])
+	if err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", url.URL{}), errors.Unwrap(err))
+	}
+	if len(u.schemes) > 0 && !containsString(u.schemes, v.Scheme) {
+		return formatParseError(values[0], fmt.Sprintf("%T", url.URL{}),
+			fmt.Errorf("scheme must be one of %s", strings.Join(u.schemes, ", ")))
+	}
+	*u.value = *v
+	return nil
+}
+
+func (u *urlValue) Get() interface{} { return *u.value }
+
+func (u *urlValue) String() string { return u.value.String() }
+
+func (u *urlValue) target() interface{} { return u.value }
+
+func (u *urlValue) setSchemes(schemes []string) { u.schemes = schemes }
+
+func (u *urlValue) constraints() map[string]string {
+	if len(u.schemes) == 0 {
+		return nil
+	}
+	return map[string]string{schemesKey: strings.Join(u.schemes, optNameSep)}
+}
+
+func containsString(list []string, s string) bool {
+	for _, elem := range list {
+		if elem == s {
+			return true
+		}
+	}
+	return false
+}
+
+// stdFlagValue adapts a standard library flag.Value to the Value interface. Set of the adapted
+// value is called once for each argument.
+type stdFlagValue struct {
+	value stdflag.Value
+}
+
+func newStdFlagValue(v stdflag.Value) *stdFlagValue {
+	return &stdFlagValue{value: v}
+}
+
+// Set calls Set of the adapted value with "true" if it is a boolean flag and no arguments are given.
+func (sv *stdFlagValue) Set(values ...string) error {
+	if bf, ok := sv.value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() && len(values) == 0 {
+		values = []string{"true"}
+	}
+	for _, val := range values {
+		if err := sv.value.Set(val); err != nil {
+			return formatParseError(val, fmt.Sprintf("%T", sv.Get()), err)
+		}
+	}
+	return nil
+}
+
+func (sv *stdFlagValue) Get() interface{} {
+	if g, ok := sv.value.(stdflag.Getter); ok {
+		return g.Get()
+	}
+	return pointee(sv.value)
+}
+
+func (sv *stdFlagValue) String() string { return sv.value.String() }
+
+func (sv *stdFlagValue) target() interface{} { return sv.value }
+
+// textValue adapts an encoding.TextUnmarshaler to the Value interface. If the type also implements
+// encoding.TextMarshaler then it is used for the string representation.
+type textValue struct {
+	value encoding.TextUnmarshaler
+	// text form of the default value if the type implements encoding.TextMarshaler. A copy of the
+	// variable cannot be used to restore the default since types like big.Int reuse their internal
+	// storage when set.
+	defText []byte
+	defCopy reflect.Value // used if there is no text form
+}
+
+func newTextValue(v encoding.TextUnmarshaler) *textValue {
+	tv := &textValue{value: v, defCopy: copyPointee(v)}
+	if m, ok := v.(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			tv.defText = append([]byte{}, text...)
+		}
+	}
+	return tv
+}
+
+func (tv *textValue) Set(values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if err := tv.value.UnmarshalText([]byte(values[0])); err != nil {
+		return formatParseError(values[0], fmt.Sprintf("%T", tv.Get()), err)
+	}
+	return nil
+}
+
+func (tv *textValue) Get() interface{} { return pointee(tv.value) }
+
+func (tv *textValue) String() string {
+	if m, ok := tv.value.(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(tv.Get())
+}
+
+func (tv *textValue) target() interface{} { return tv.value }
+
+// reset restores the variable to the value it had when tv was created.
+func (tv *textValue) reset() {
+	if tv.defText != nil && tv.value.UnmarshalText(tv.defText) == nil {
+		return
+	}
+	if tv.defCopy.IsValid() {
+		reflect.ValueOf(tv.value).Elem().Set(copyReflectValue(tv.defCopy))
+	}
+}
+
+// sliceValue implements the Value interface for slices and arrays of any type supported by
+// newValue. Each argument is set on a separate element using the element type's Value. Arrays
+// require exactly as many arguments as their length.
+type sliceValue struct {
+	value reflect.Value // the addressable slice or array variable
+	// keys of the struct tag applied to the Value of each element, see applyValueKeys
+	keyValues map[string]string
+}
+
+func newSliceValue(v reflect.Value) (*sliceValue, error) {
+	// make sure that the element type is supported
+	if _, err := newValue(reflect.New(v.Type().Elem()).Interface()); err != nil {
+		return nil, err
+	}
+	return &sliceValue{value: v}, nil
+}
+
+// elemValue returns the Value of elem which is an addressable element of a slice or array.
+func (sv *sliceValue) elemValue(elem reflect.Value) Value {
+	// element type has been verified by newSliceValue
+	val, _ := newValue(elem.Addr().Interface())
+	// keys have been verified by applyValueKeys
+	val, _ = applyValueKeys(val, sv.keyValues)
+	return val
+}
+
+func (sv *sliceValue) Set(values ...string) error {
+	typ := sv.value.Type()
+	var newVal reflect.Value
+	if typ.Kind() == reflect.Array {
+		if len(values) != typ.Len() {
+			return fmt.Errorf("type '%s' requires exactly %d argument(s), given %d", typ, typ.Len(), len(values))
+		}
+		newVal = reflect.New(typ).Elem()
+	} else {
+		newVal = reflect.MakeSlice(typ, len(values), len(values))
+	}
+	for i, val := range values {
+		if err := sv.elemValue(newVal.Index(i)).Set(val); err != nil {
+			return err
+		}
+	}
+	sv.value.Set(newVal)
+	return nil
+}
+
+func (sv *sliceValue) Get() interface{} { return sv.value.Interface() }
+
+func (sv *sliceValue) String() string {
+	elems := make([]string, sv.value.Len())
+	for i := range elems {
+		elems[i] = sv.elemValue(sv.value.Index(i)).String()
+	}
+	return "[" + strings.Join(elems, " ") + "]"
+}
+
+func (sv *sliceValue) target() interface{} { return sv.value.Addr().Interface() }
+
+func (sv *sliceValue) constraints() map[string]string {
+	if cv, ok := sv.elemValue(reflect.New(sv.value.Type().Elem()).Elem()).(constrainedValue); ok {
+		return cv.constraints()
+	}
+	return nil
+}
+
+// defaultNArgs returns the length of an array so that it becomes the nargs of the flag.
+func (sv *sliceValue) defaultNArgs() int {
+	if sv.value.Kind() == reflect.Array {
+		return sv.value.Len()
+	}
+	return 1
+}
+
+// mapValue implements the Value interface for maps with keys of kind string and values of any type
+// supported by newValue. Each argument is a key=value pair, a "=" in the key can be escaped with a
+// backslash. Arguments of all occurrences of the flag are collected, for duplicate keys the last
+// value wins unless duplicates are rejected.
+type mapValue struct {
+	value         reflect.Value // the addressable map variable
+	rejectDupKeys bool
+}
+
+func newMapValue(v reflect.Value) (*mapValue, error) {
+	if v.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("type '%s' must have keys of kind string", v.Type())
+	}
+	// make sure that the element type is supported
+	if _, err := newValue(reflect.New(v.Type().Elem()).Interface()); err != nil {
+		return nil, err
+	}
+	return &mapValue{value: v}, nil
+}
+
+func (mv *mapValue) Set(values ...string) error {
+	typ := mv.value.Type()
+	newMap := reflect.MakeMapWithSize(typ, len(values))
+	for _, val := range values {
+		k, v, ok := splitKeyValue(val)
+		if !ok || k == "" {
+			return formatParseError(val, typ.String(), fmt.Errorf("expected key%cvalue", kvSep))
+		}
+		key := reflect.ValueOf(k).Convert(typ.Key())
+		if mv.rejectDupKeys && newMap.MapIndex(key).IsValid() {
+			return formatParseError(val, typ.String(), fmt.Errorf("duplicate key '%s'", k))
+		}
+		elem := reflect.New(typ.Elem())
+		// element type has been verified by newMapValue
+		elemVal, _ := newValue(elem.Interface())
+		if err := elemVal.Set(v); err != nil {
+			return err
+		}
+		newMap.SetMapIndex(key, elem.Elem())
+	}
+	mv.value.Set(newMap)
+	return nil
+}
+
+// splitKeyValue splits s at the first kvSep which is not preceded by a backslash. The backslashes
+// escaping a kvSep in the key are removed, the value is returned as is and may be empty.
+func splitKeyValue(s string) (key string, value string, ok bool) {
+	b := &strings.Builder{}
+	for i, r := range s {
+		switch {
+		case r == '\\' && strings.HasPrefix(s[i+1:], string(kvSep)):
+			// the escaped kvSep is written in the next iteration
+		case r == kvSep && !strings.HasSuffix(s[:i], "\\"):
+			return b.String(), s[i+1:], true
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return "", "", false
+}
+
+func (mv *mapValue) Get() interface{} { return mv.value.Interface() }
+
+// String returns the pairs sorted by key, for e.g. "[a=1 b=2]".
+func (mv *mapValue) String() string {
+	keys := mv.value.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		elem := reflect.New(mv.value.Type().Elem())
+		elem.Elem().Set(mv.value.MapIndex(key))
+		elemVal, _ := newValue(elem.Interface())
+		pairs[i] = fmt.Sprintf("%s%c%s", key.String(), kvSep, elemVal.String())
+	}
+	return "[" + strings.Join(pairs, " ") + "]"
+}
+
+func (mv *mapValue) target() interface{} { return mv.value.Addr().Interface() }
+
+func (mv *mapValue) accumulates() bool { return true }
+
+func (mv *mapValue) setRejectDupKeys(reject bool) { mv.rejectDupKeys = reject }
+
+func (mv *mapValue) constraints() map[string]string {
+	if mv.rejectDupKeys {
+		return map[string]string{dupKeysKey: "error"}
+	}
+	return nil
+}
+
+// ptrValue implements the Value interface for pointers to any type supported by newValue. The
+// pointer stays nil until the flag is given, so that an unset flag can be told apart from one set to
+// the zero value. Each Set points it to a newly allocated variable, the variable pointed to before is
+// never modified.
+type ptrValue struct {
+	value reflect.Value // the addressable pointer variable
+	// Value of a variable of the pointed to type, used for properties of the Value like nargs. It is
+	// configured as per keyValues.
+	elem Value
+	// keys of the struct tag applied to the Value of each pointed to variable, see applyValueKeys
+	keyValues map[string]string
+}
+
+func newPtrValue(v reflect.Value) (*ptrValue, error) {
+	elem, err := newValue(reflect.New(v.Type().Elem()).Interface())
+	if err != nil {
+		return nil, err
+	}
+	return &ptrValue{value: v, elem: elem}, nil
+}
+
+// elemValue returns the Value of the variable pointed to by ptr.
+func (pv *ptrValue) elemValue(ptr reflect.Value) Value {
+	// pointed to type has been verified by newPtrValue
+	val, _ := newValue(ptr.Interface())
+	// keys have been verified by applyValueKeys
+	val, _ = applyValueKeys(val, pv.keyValues)
+	return val
+}
+
+func (pv *ptrValue) Set(values ...string) error {
+	newVar := reflect.New(pv.value.Type().Elem())
+	if err := pv.elemValue(newVar).Set(values...); err != nil {
+		return err
+	}
+	pv.value.Set(newVar)
+	return nil
+}
+
+func (pv *ptrValue) Get() interface{} { return pv.value.Interface() }
+
+// String returns "none" if the pointer is nil.
+func (pv *ptrValue) String() string {
+	if pv.value.IsNil() {
+		return "none"
+	}
+	return pv.elemValue(pv.value).String()
+}
+
+func (pv *ptrValue) target() interface{} { return pv.value.Addr().Interface() }
+
+func (pv *ptrValue) accumulates() bool {
+	av, ok := pv.elem.(accumValue)
+	return ok && av.accumulates()
+}
+
+func (pv *ptrValue) defaultNArgs() int {
+	if nv, ok := pv.elem.(nargsValue); ok {
+		return nv.defaultNArgs()
+	}
+	return 1
+}
+
+func (pv *ptrValue) constraints() map[string]string {
+	if cv, ok := pv.elem.(constrainedValue); ok {
+		return cv.constraints()
+	}
+	return nil
+}
+
+// pointee returns the variable pointed to by ptr or ptr itself if it is not a pointer.
+func pointee(ptr interface{}) interface{} {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ptr
+	}
+	return v.Elem().Interface()
+}
@@ -3,8 +3,13 @@ package flagparse
 import (
 	"fmt"
 	"math"
+	"math/big"
+	"net"
+	"net/url"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -28,6 +33,48 @@ func (c *customValue) Get() interface{} {
 	return nil
 }
 
+// testLevel implements encoding.TextUnmarshaler and encoding.TextMarshaler
+type testLevel int
+
+var testLevelNames = []string{"debug", "info", "error"}
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	for i, name := range testLevelNames {
+		if name == string(text) {
+			*l = testLevel(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown level")
+}
+
+func (l testLevel) MarshalText() ([]byte, error) { return []byte(testLevelNames[l]), nil }
+
+// testStdList implements the standard library's flag.Value interface
+type testStdList []string
+
+func (sl *testStdList) Set(val string) error {
+	if val == "" {
+		return fmt.Errorf("empty element")
+	}
+	*sl = append(*sl, val)
+	return nil
+}
+
+func (sl *testStdList) String() string { return strings.Join(*sl, ",") }
+
+// testStdBool implements the standard library's flag.Value interface as a boolean flag
+type testStdBool bool
+
+func (b *testStdBool) Set(val string) error {
+	*b = val == "true"
+	return nil
+}
+
+func (b *testStdBool) String() string { return fmt.Sprint(bool(*b)) }
+
+func (b *testStdBool) IsBoolFlag() bool { return true }
+
 func TestNewValue_SupportedType(t *testing.T) {
 	// Test value creation for types implementing Value interface
 	supported := []interface{}{
@@ -40,6 +87,51 @@ func TestNewValue_SupportedType(t *testing.T) {
 		new([]string),
 		new(float64),
 		new([]float64),
+		new(int8),
+		new([]int8),
+		new(int16),
+		new([]int16),
+		new(int32),
+		new([]int32),
+		new(int64),
+		new([]int64),
+		new(uint),
+		new([]uint),
+		new(uint8),
+		new([]uint8),
+		new(uint16),
+		new([]uint16),
+		new(uint32),
+		new([]uint32),
+		new(uint64),
+		new([]uint64),
+		new(uintptr),
+		new([]uintptr),
+		new(time.Duration),
+		new([]time.Duration),
+		new(time.Time),
+		new([]time.Time),
+		new(testLevel),
+		new(testStdList),
+		new(big.Int),
+		new([]testLevel),
+		new([3]float64),
+		new([]customValue),
+		new([2][]int),
+		new(map[string]string),
+		new(map[string][]time.Duration),
+		new(*int),
+		new(*[]string),
+		new(**time.Time),
+		new(net.IP),
+		new([]net.IP),
+		new(net.IPNet),
+		new([]net.IPNet),
+		new(HostPort),
+		new([]HostPort),
+		new(url.URL),
+		new(*url.URL),
+		new([]*url.URL),
 	}
 	for _, val := range supported {
 		_, err := newValue(val)
@@ -51,10 +143,12 @@ func TestNewValue_SupportedType(t *testing.T) {
 
 func TestNewValue_UnsupportedType(t *testing.T) {
 	type unsupported struct{}
-	var testVar unsupported
-	_, err := newValue(&testVar)
-	if err == nil {
-		t.Errorf("Expected: newValue(%T) should reult in error, Got: no error", testVar)
+	for _, testVar := range []interface{}{new(unsupported), new([]unsupported), new([2]complex64),
+		new(map[int]string), new(map[string]complex64), new(*complex64)} {
+		_, err := newValue(testVar)
+		if err == nil {
+			t.Errorf("Expected: newValue(%T) should reult in error, Got: no error", testVar)
+		}
 	}
 }
 
@@ -269,6 +363,86 @@ func TestIntListType(t *testing.T) {
 	}
 }
 
+func TestSizedIntTypes(t *testing.T) {
+	data := []struct {
+		value    Value
+		input    string
+		expected interface{}
+		overflow string
+	}{
+		{newInt8Value(new(int8)), "-128", int8(math.MinInt8), "128"},
+		{newInt16Value(new(int16)), "32767", int16(math.MaxInt16), "32768"},
+		{newInt32Value(new(int32)), "-2147483648", int32(math.MinInt32), "2147483648"},
+		{newInt64Value(new(int64)), "9223372036854775807", int64(math.MaxInt64), "9223372036854775808"},
+		{newUintValue(new(uint)), fmt.Sprint(maxUint), maxUint, "-1"},
+		{newUint8Value(new(uint8)), "255", uint8(math.MaxUint8), "256"},
+		{newUint16Value(new(uint16)), "65535", uint16(math.MaxUint16), "65536"},
+		{newUint32Value(new(uint32)), "4294967295", uint32(math.MaxUint32), "4294967296"},
+		{newUint64Value(new(uint64)), "18446744073709551615", uint64(math.MaxUint64), "18446744073709551616"},
+		{newUintptrValue(new(uintptr)), "10", uintptr(10), "-10"},
+	}
+
+	for _, val := range data {
+		if err := val.value.Set(val.input); err != nil {
+			t.Errorf("Expected: no error for %T.Set(%q); Got: error %q", val.value, val.input, err)
+		}
+		if val.value.Get() != val.expected {
+			t.Errorf("Expected: Get() should return the value %v; Got: %v", val.expected, val.value.Get())
+		}
+		if val.value.String() != val.input {
+			t.Errorf("Expected: %T.String() should return the string %q, Got: %q", val.value, val.input, val.value.String())
+		}
+
+		for _, input := range []string{"hello", "1.1", val.overflow} {
+			if err := val.value.Set(input); err == nil {
+				t.Errorf("Expected: %T.Set(%q) should result in error, Got: no error", val.value, input)
+			}
+		}
+	}
+
+	// Test that overflow errors name the target type
+	err := newInt8Value(new(int8)).Set("300")
+	expected := "cannot parse '300' as type 'int8': value out of range"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected: error %q, Got: %v", expected, err)
+	}
+}
+
+func TestSizedIntListTypes(t *testing.T) {
+	data := []struct {
+		value    Value
+		input    []string
+		expected interface{}
+		invalid  []string
+	}{
+		{newInt8ListValue(new([]int8)), []string{"-128", "0", "127"}, []int8{-128, 0, 127}, []string{"1", "128"}},
+		{newInt16ListValue(new([]int16)), []string{"-1", "0x10"}, []int16{-1, 16}, []string{"40000"}},
+		{newInt32ListValue(new([]int32)), []string{"1", "-2"}, []int32{1, -2}, []string{"hello"}},
+		{newInt64ListValue(new([]int64)), []string{"1", "-2"}, []int64{1, -2}, []string{"1.1"}},
+		{newUintListValue(new([]uint)), []string{"1", "2"}, []uint{1, 2}, []string{"-1"}},
+		{newUint8ListValue(new([]uint8)), []string{"0", "255"}, []uint8{0, 255}, []string{"256"}},
+		{newUint16ListValue(new([]uint16)), []string{"0", "65535"}, []uint16{0, 65535}, []string{"65536"}},
+		{newUint32ListValue(new([]uint32)), []string{"1", "2"}, []uint32{1, 2}, []string{"4294967296"}},
+		{newUint64ListValue(new([]uint64)), []string{"1", "2"}, []uint64{1, 2}, []string{"-2"}},
+		{newUintptrListValue(new([]uintptr)), []string{"1", "2"}, []uintptr{1, 2}, []string{"true"}},
+	}
+
+	for _, val := range data {
+		if err := val.value.Set(val.input...); err != nil {
+			t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, val.input)
+		}
+		if !reflect.DeepEqual(val.value.Get(), val.expected) {
+			t.Errorf("Expected: Get() should return the value %v; Got: %v", val.expected, val.value.Get())
+		}
+		if fmt.Sprint(val.expected) != val.value.String() {
+			t.Errorf("Expected: %v, Got: %v", val.expected, val.value.String())
+		}
+		if err := val.value.Set(val.invalid...); err == nil {
+			t.Errorf("Expected: error, Got: no error for input \"%s\"", val.invalid)
+		}
+	}
+}
+
 func TestFloat64Type(t *testing.T) {
 	var testVar float64
 	testVal := newFloat64Value(&testVar)
@@ -343,3 +517,580 @@ func TestFloat64ListType(t *testing.T) {
 		t.Errorf("Expected: error, Got: no error for input \"%s\"", input)
 	}
 }
+
+func TestDurationType(t *testing.T) {
+	var testVar time.Duration
+	testVal := newDurationValue(&testVar)
+
+	data := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"0s", 0},
+		{"1h30m0s", 90 * time.Minute},
+		{"-1.5s", -1500 * time.Millisecond},
+	}
+
+	// Test valid values
+	for _, val := range data {
+		if err := testVal.Set(val.input); err != nil {
+			t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, val.input)
+		}
+		if val.expected != testVar {
+			t.Errorf("Expected: %v, Got: %v", val.expected, testVar)
+		}
+		if testVal.Get() != val.expected {
+			t.Errorf("Expected: Get() should return the value %v; Got: %v", val.expected, testVal.Get())
+		}
+		if val.input != testVal.String() {
+			t.Errorf("Expected: %v, Got: %v", val.input, testVal.String())
+		}
+	}
+
+	// Test invalid values
+	for _, input := range []string{"hello", "10", "1x", ""} {
+		if err := testVal.Set(input); err == nil {
+			t.Errorf("Expected: error, Got: no error for input \"%s\"", input)
+		}
+	}
+}
+
+func TestDurationListType(t *testing.T) {
+	var testVar []time.Duration
+	testVal := newDurationListValue(&testVar)
+	data := struct {
+		input    []string
+		expected []time.Duration
+	}{
+		input:    []string{"1s", "2m0s", "-3ms"},
+		expected: []time.Duration{time.Second, 2 * time.Minute, -3 * time.Millisecond},
+	}
+
+	// Test valid values
+	if err := testVal.Set(data.input...); err != nil {
+		t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, data.input)
+	}
+	if !reflect.DeepEqual(data.expected, testVar) {
+		t.Errorf("Expected: %v, Got: %v", data.expected, testVar)
+	}
+	if !reflect.DeepEqual(testVal.Get(), testVar) {
+		t.Errorf("Expected: Get() should return the value %v; Got: %v", testVar, testVal.Get())
+	}
+	if fmt.Sprint(data.input) != testVal.String() {
+		t.Errorf("Expected: %v, Got: %v", data.input, testVal.String())
+	}
+
+	// Test invalid values
+	input := []string{"1s", "hello"}
+	if err := testVal.Set(input...); err == nil {
+		t.Errorf("Expected: error, Got: no error for input \"%s\"", input)
+	}
+}
+
+func TestTimeType(t *testing.T) {
+	var testVar time.Time
+	testVal := newTimeValue(&testVar)
+
+	if testVal.String() != "" {
+		t.Errorf("Expected: empty string for zero time, Got: %v", testVal.String())
+	}
+
+	data := []struct {
+		layout   string
+		input    string
+		expected time.Time
+	}{
+		{time.RFC3339, "2020-01-02T03:04:05Z", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{time.RFC3339, "2020-01-02T03:04:05+01:00", time.Date(2020, 1, 2, 2, 4, 5, 0, time.UTC)},
+		{"2006-01-02", "2021-12-31", time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{time.Kitchen, "3:04PM", time.Date(0, 1, 1, 15, 4, 0, 0, time.UTC)},
+	}
+
+	// Test valid values
+	for _, val := range data {
+		testVal.setLayout(val.layout)
+		if err := testVal.Set(val.input); err != nil {
+			t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, val.input)
+		}
+		if !val.expected.Equal(testVar) {
+			t.Errorf("Expected: %v, Got: %v", val.expected, testVar)
+		}
+		if !val.expected.Equal(testVal.Get().(time.Time)) {
+			t.Errorf("Expected: Get() should return the value %v; Got: %v", val.expected, testVal.Get())
+		}
+		if val.input != testVal.String() {
+			t.Errorf("Expected: %v, Got: %v", val.input, testVal.String())
+		}
+	}
+
+	// Test invalid values
+	testVal.setLayout(time.RFC3339)
+	for _, input := range []string{"hello", "2020-01-02", "2020-13-02T03:04:05Z"} {
+		if err := testVal.Set(input); err == nil {
+			t.Errorf("Expected: error, Got: no error for input \"%s\"", input)
+		}
+	}
+}
+
+func TestTimeListType(t *testing.T) {
+	var testVar []time.Time
+	testVal := newTimeListValue(&testVar)
+	testVal.setLayout("2006-01-02")
+	data := struct {
+		input    []string
+		expected []time.Time
+	}{
+		input:    []string{"2020-01-01", "2020-12-31"},
+		expected: []time.Time{time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)},
+	}
+
+	// Test valid values
+	if err := testVal.Set(data.input...); err != nil {
+		t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, data.input)
+	}
+	if !reflect.DeepEqual(data.expected, testVar) {
+		t.Errorf("Expected: %v, Got: %v", data.expected, testVar)
+	}
+	if !reflect.DeepEqual(testVal.Get(), testVar) {
+		t.Errorf("Expected: Get() should return the value %v; Got: %v", testVar, testVal.Get())
+	}
+	if fmt.Sprint(data.input) != testVal.String() {
+		t.Errorf("Expected: %v, Got: %v", data.input, testVal.String())
+	}
+
+	// Test invalid values
+	input := []string{"2020-01-01", "2020-01-01T00:00:00Z"}
+	if err := testVal.Set(input...); err == nil {
+		t.Errorf("Expected: error, Got: no error for input \"%s\"", input)
+	}
+}
+
+func TestTextType(t *testing.T) {
+	var testVar testLevel
+	testVal, err := newValue(&testVar)
+	if err != nil {
+		t.Fatalf("Expected: newValue(%T) should succeed, Got: %s", &testVar, err)
+	}
+	if testVal.String() != "debug" {
+		t.Errorf("Expected: String() should use MarshalText, Got: %v", testVal.String())
+	}
+
+	// Test valid values
+	for i, input := range testLevelNames {
+		if err := testVal.Set(input); err != nil {
+			t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, input)
+		}
+		if testVar != testLevel(i) {
+			t.Errorf("Expected: %v, Got: %v", testLevel(i), testVar)
+		}
+		if testVal.Get() != testLevel(i) {
+			t.Errorf("Expected: Get() should return the value %v; Got: %v", testLevel(i), testVal.Get())
+		}
+		if input != testVal.String() {
+			t.Errorf("Expected: %v, Got: %v", input, testVal.String())
+		}
+	}
+
+	// Test invalid values
+	err = testVal.Set("fatal")
+	expected := "cannot parse 'fatal' as type 'flagparse.testLevel': unknown level"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected: error %q, Got: %v", expected, err)
+	}
+
+	// Test types without MarshalText
+	var n big.Int
+	testVal = newTextValue(&n)
+	if err := testVal.Set("123456789012345678901234567890"); err != nil {
+		t.Errorf("Expected: no error, Got: error '%s'", err)
+	}
+	if testVal.String() != "123456789012345678901234567890" {
+		t.Errorf("Expected: %v, Got: %v", "123456789012345678901234567890", testVal.String())
+	}
+}
+
+func TestStdFlagType(t *testing.T) {
+	var testVar testStdList
+	testVal, err := newValue(&testVar)
+	if err != nil {
+		t.Fatalf("Expected: newValue(%T) should succeed, Got: %s", &testVar, err)
+	}
+
+	// Test valid values, Set of the adapted value is called for each argument
+	input := []string{"a", "b", "c"}
+	if err := testVal.Set(input...); err != nil {
+		t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, input)
+	}
+	if !reflect.DeepEqual(testVar, testStdList(input)) {
+		t.Errorf("Expected: %v, Got: %v", input, testVar)
+	}
+	if !reflect.DeepEqual(testVal.Get(), testStdList(input)) {
+		t.Errorf("Expected: Get() should return the value %v; Got: %v", input, testVal.Get())
+	}
+	if testVal.String() != "a,b,c" {
+		t.Errorf("Expected: %v, Got: %v", "a,b,c", testVal.String())
+	}
+
+	// Test invalid values
+	if err := testVal.Set("d", ""); err == nil {
+		t.Errorf("Expected: error, Got: no error for input \"%s\"", []string{"d", ""})
+	}
+
+	// Test boolean flags being set without arguments
+	var b testStdBool
+	testVal = newStdFlagValue(&b)
+	if err := testVal.Set(); err != nil || !b {
+		t.Errorf("Expected: Set() should set boolean flag to true, Got: %v, error %v", b, err)
+	}
+}
+
+func TestSliceType(t *testing.T) {
+	var testVar []testLevel
+	testVal, err := newValue(&testVar)
+	if err != nil {
+		t.Fatalf("Expected: newValue(%T) should succeed, Got: %s", &testVar, err)
+	}
+	data := struct {
+		input    []string
+		expected []testLevel
+	}{
+		input:    []string{"error", "debug", "info"},
+		expected: []testLevel{2, 0, 1},
+	}
+
+	// Test valid values
+	if err := testVal.Set(data.input...); err != nil {
+		t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, data.input)
+	}
+	if !reflect.DeepEqual(data.expected, testVar) {
+		t.Errorf("Expected: %v, Got: %v", data.expected, testVar)
+	}
+	if !reflect.DeepEqual(testVal.Get(), testVar) {
+		t.Errorf("Expected: Get() should return the value %v; Got: %v", testVar, testVal.Get())
+	}
+	if fmt.Sprint(data.input) != testVal.String() {
+		t.Errorf("Expected: %v, Got: %v", data.input, testVal.String())
+	}
+
+	// Test invalid values, the variable must be left untouched
+	input := []string{"info", "fatal"}
+	if err := testVal.Set(input...); err == nil {
+		t.Errorf("Expected: error, Got: no error for input \"%s\"", input)
+	}
+	if !reflect.DeepEqual(data.expected, testVar) {
+		t.Errorf("Expected: %v after invalid input, Got: %v", data.expected, testVar)
+	}
+}
+
+func TestArrayType(t *testing.T) {
+	var testVar [3]float64
+	testVal, err := newValue(&testVar)
+	if err != nil {
+		t.Fatalf("Expected: newValue(%T) should succeed, Got: %s", &testVar, err)
+	}
+	if nv, ok := testVal.(nargsValue); !ok || nv.defaultNArgs() != 3 {
+		t.Errorf("Expected: array length to be the default nargs, Got: %v", testVal)
+	}
+
+	// Test valid values
+	input := []string{"1.5", "-2", "0"}
+	expected := [3]float64{1.5, -2, 0}
+	if err := testVal.Set(input...); err != nil {
+		t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, input)
+	}
+	if testVar != expected {
+		t.Errorf("Expected: %v, Got: %v", expected, testVar)
+	}
+	if testVal.Get() != expected {
+		t.Errorf("Expected: Get() should return the value %v; Got: %v", expected, testVal.Get())
+	}
+	if fmt.Sprint(input) != testVal.String() {
+		t.Errorf("Expected: %v, Got: %v", input, testVal.String())
+	}
+
+	// Test invalid values
+	for _, input := range [][]string{{"1", "2"}, {"1", "2", "3", "4"}, {"1", "2", "x"}} {
+		if err := testVal.Set(input...); err == nil {
+			t.Errorf("Expected: error, Got: no error for input \"%s\"", input)
+		}
+	}
+}
+
+func TestMapType(t *testing.T) {
+	var testVar map[string]int
+	testVal, err := newValue(&testVar)
+	if err != nil {
+		t.Fatalf("Expected: newValue(%T) should succeed, Got: %s", &testVar, err)
+	}
+	data := struct {
+		input    []string
+		expected map[string]int
+	}{
+		input:    []string{"b=2", "a=1", "a\\=b=0x10", "b=3"},
+		expected: map[string]int{"a": 1, "a=b": 16, "b": 3},
+	}
+
+	// Test valid values, the last value of a duplicate key wins
+	if err := testVal.Set(data.input...); err != nil {
+		t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, data.input)
+	}
+	if !reflect.DeepEqual(data.expected, testVar) {
+		t.Errorf("Expected: %v, Got: %v", data.expected, testVar)
+	}
+	if !reflect.DeepEqual(testVal.Get(), testVar) {
+		t.Errorf("Expected: Get() should return the value %v; Got: %v", testVar, testVal.Get())
+	}
+	if testVal.String() != "[a=1 a=b=16 b=3]" {
+		t.Errorf("Expected: %v, Got: %v", "[a=1 a=b=16 b=3]", testVal.String())
+	}
+
+	// Test invalid values
+	for _, input := range [][]string{{"a"}, {"a=1=2"}, {"=1"}, {"a="}, {"a=x"}} {
+		if err := testVal.Set(input...); err == nil {
+			t.Errorf("Expected: error, Got: no error for input \"%s\"", input)
+		}
+	}
+
+	// Test that only the first unescaped "=" separates key and value
+	var strMap map[string]string
+	strVal, _ := newValue(&strMap)
+	input := []string{"k=a=b", "empty=", "p=C:\\dir", "tok=abc==", "a\\=b=c\\=d"}
+	expectedStr := map[string]string{"k": "a=b", "empty": "", "p": "C:\\dir", "tok": "abc==", "a=b": "c\\=d"}
+	if err := strVal.Set(input...); err != nil || !reflect.DeepEqual(strMap, expectedStr) {
+		t.Errorf("Expected: %q, Got: %q, error %v for input %q", expectedStr, strMap, err, input)
+	}
+
+	// Test rejection of duplicate keys
+	testVal.(dupKeysValue).setRejectDupKeys(true)
+	err = testVal.Set("a=1", "a=2")
+	expected := "cannot parse 'a=2' as type 'map[string]int': duplicate key 'a'"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected: error %q, Got: %v", expected, err)
+	}
+}
+
+func TestPtrType(t *testing.T) {
+	var testVar *int
+	testVal, err := newValue(&testVar)
+	if err != nil {
+		t.Fatalf("Expected: newValue(%T) should succeed, Got: %s", &testVar, err)
+	}
+	if testVal.String() != "none" {
+		t.Errorf("Expected: String() of nil pointer should be %q, Got: %q", "none", testVal.String())
+	}
+
+	// Test valid values, each Set must allocate a new variable
+	var prev *int
+	for _, val := range []struct {
+		input    string
+		expected int
+	}{{"0", 0}, {"10", 10}, {"-10", -10}} {
+		if err := testVal.Set(val.input); err != nil {
+			t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, val.input)
+		}
+		if testVar == nil || *testVar != val.expected {
+			t.Errorf("Expected: pointer to %v, Got: %v", val.expected, testVar)
+		}
+		if testVar == prev {
+			t.Errorf("Expected: Set() should allocate a new variable, Got: same pointer")
+		}
+		prev = testVar
+		if testVal.Get() != testVar {
+			t.Errorf("Expected: Get() should return the value %v; Got: %v", testVar, testVal.Get())
+		}
+		if val.input != testVal.String() {
+			t.Errorf("Expected: %v, Got: %v", val.input, testVal.String())
+		}
+	}
+
+	// Test invalid values, the pointer must be left untouched
+	if err := testVal.Set("hello"); err == nil {
+		t.Errorf("Expected: error, Got: no error for input \"%s\"", "hello")
+	}
+	if *testVar != -10 {
+		t.Errorf("Expected: %v after invalid input, Got: %v", -10, *testVar)
+	}
+
+	// Test keys of the struct tag being applied to the pointed to type
+	var since *time.Time
+	testVal, _ = newValue(&since)
+	if _, err := applyValueKeys(testVal, map[string]string{layoutKey: "2006-01-02"}); err != nil {
+		t.Errorf("Expected: no error for layout of %T, Got: %s", since, err)
+	}
+	if err := testVal.Set("2020-01-02"); err != nil || testVal.String() != "2020-01-02" {
+		t.Errorf("Expected: %v, Got: %v, error %v", "2020-01-02", testVal.String(), err)
+	}
+	testVal, _ = newValue(new(*int))
+	if _, err := applyValueKeys(testVal, map[string]string{layoutKey: "2006"}); err == nil {
+		t.Errorf("Expected: error for layout of *int, Got: no error")
+	}
+}
+
+func TestIPType(t *testing.T) {
+	var testVar net.IP
+	testVal := newIPValue(&testVar)
+	if testVal.String() != "" {
+		t.Errorf("Expected: empty string for nil IP, Got: %v", testVal.String())
+	}
+
+	// Test valid values
+	for _, input := range []string{"127.0.0.1", "::1", "2001:db8::68"} {
+		if err := testVal.Set(input); err != nil {
+			t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, input)
+		}
+		if !testVar.Equal(net.ParseIP(input)) {
+			t.Errorf("Expected: %v, Got: %v", input, testVar)
+		}
+		if !reflect.DeepEqual(testVal.Get(), testVar) {
+			t.Errorf("Expected: Get() should return the value %v; Got: %v", testVar, testVal.Get())
+		}
+		if input != testVal.String() {
+			t.Errorf("Expected: %v, Got: %v", input, testVal.String())
+		}
+	}
+
+	// Test invalid values
+	for _, input := range []string{"hello", "256.0.0.1", "1.2.3", ""} {
+		if err := testVal.Set(input); err == nil {
+			t.Errorf("Expected: error, Got: no error for input \"%s\"", input)
+		}
+	}
+}
+
+func TestIPNetType(t *testing.T) {
+	var testVar net.IPNet
+	testVal := newIPNetValue(&testVar)
+	if testVal.String() != "" {
+		t.Errorf("Expected: empty string for zero IPNet, Got: %v", testVal.String())
+	}
+
+	data := []struct {
+		input    string
+		expected string
+	}{
+		{"10.0.0.0/8", "10.0.0.0/8"},
+		{"192.168.1.10/24", "192.168.1.0/24"},
+		{"2001:db8::/32", "2001:db8::/32"},
+	}
+
+	// Test valid values, host bits are masked
+	for _, val := range data {
+		if err := testVal.Set(val.input); err != nil {
+			t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, val.input)
+		}
+		if testVar.String() != val.expected {
+			t.Errorf("Expected: %v, Got: %v", val.expected, testVar.String())
+		}
+		if !reflect.DeepEqual(testVal.Get(), testVar) {
+			t.Errorf("Expected: Get() should return the value %v; Got: %v", testVar, testVal.Get())
+		}
+		if val.expected != testVal.String() {
+			t.Errorf("Expected: %v, Got: %v", val.expected, testVal.String())
+		}
+	}
+
+	// Test invalid values
+	for _, input := range []string{"10.0.0.0", "10.0.0.0/33", "hello/8"} {
+		if err := testVal.Set(input); err == nil {
+			t.Errorf("Expected: error, Got: no error for input \"%s\"", input)
+		}
+	}
+}
+
+func TestHostPortType(t *testing.T) {
+	var testVar HostPort
+	testVal := newHostPortValue(&testVar)
+	if testVal.String() != "" {
+		t.Errorf("Expected: empty string for zero HostPort, Got: %v", testVal.String())
+	}
+
+	data := []struct {
+		defPort  string
+		input    string
+		expected HostPort
+		str      string
+	}{
+		{"", "localhost:80", HostPort{"localhost", "80"}, "localhost:80"},
+		{"", "[::1]:http", HostPort{"::1", "http"}, "[::1]:http"},
+		{"", ":8080", HostPort{"", "8080"}, ":8080"},
+		{"443", "example.com", HostPort{"example.com", "443"}, "example.com:443"},
+		{"443", "example.com:8443", HostPort{"example.com", "8443"}, "example.com:8443"},
+		{"443", "::1", HostPort{"::1", "443"}, "[::1]:443"},
+		{"443", "[::1]", HostPort{"::1", "443"}, "[::1]:443"},
+		{"443", "example.com:", HostPort{"example.com", "443"}, "example.com:443"},
+		{"443", "fe80::1%eth0", HostPort{"fe80::1%eth0", "443"}, "[fe80::1%eth0]:443"},
+	}
+
+	// Test valid values
+	for _, val := range data {
+		testVal.setDefPort(val.defPort)
+		if err := testVal.Set(val.input); err != nil {
+			t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, val.input)
+		}
+		if testVar != val.expected {
+			t.Errorf("Expected: %v, Got: %v", val.expected, testVar)
+		}
+		if testVal.Get() != val.expected {
+			t.Errorf("Expected: Get() should return the value %v; Got: %v", val.expected, testVal.Get())
+		}
+		if val.str != testVal.String() {
+			t.Errorf("Expected: %v, Got: %v", val.str, testVal.String())
+		}
+	}
+
+	// Test invalid values
+	testVal.setDefPort("")
+	for _, input := range []string{"localhost", "localhost:", "::1", "a:b:c", "a b:80", "host:8 0"} {
+		if err := testVal.Set(input); err == nil {
+			t.Errorf("Expected: error, Got: no error for input \"%s\"", input)
+		}
+	}
+
+	// Test that the default port is used only if the port is missing
+	testVal.setDefPort("80")
+	for _, input := range []string{"example.com:80:90", "a b c", "[example.com", "host/path"} {
+		if err := testVal.Set(input); err == nil {
+			t.Errorf("Expected: error, Got: no error for input \"%s\" with default port, value %v", input, testVar)
+		}
+	}
+}
+
+func TestURLType(t *testing.T) {
+	var testVar url.URL
+	testVal := newURLValue(&testVar)
+
+	// Test valid values
+	for _, input := range []string{"https://example.com/a?b=c", "ftp://user@host:21", "/relative/path"} {
+		if err := testVal.Set(input); err != nil {
+			t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, input)
+		}
+		if testVar.String() != input {
+			t.Errorf("Expected: %v, Got: %v", input, testVar.String())
+		}
+		if !reflect.DeepEqual(testVal.Get(), testVar) {
+			t.Errorf("Expected: Get() should return the value %v; Got: %v", testVar, testVal.Get())
+		}
+		if input != testVal.String() {
+			t.Errorf("Expected: %v, Got: %v", input, testVal.String())
+		}
+	}
+
+	// Test invalid values
+	if err := testVal.Set("http://[::1"); err == nil {
+		t.Errorf("Expected: error, Got: no error for input \"%s\"", "http://[::1")
+	}
+
+	// Test scheme allow-list
+	testVal.setSchemes([]string{"http", "https"})
+	if err := testVal.Set("https://example.com"); err != nil {
+		t.Errorf("Expected: no error, Got: error '%s' for input \"%s\"", err, "https://example.com")
+	}
+	err := testVal.Set("ftp://example.com")
+	expected := "cannot parse 'ftp://example.com' as type 'url.URL': scheme must be one of http, https"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected: error %q, Got: %v", expected, err)
+	}
+	if testVar.Scheme != "https" {
+		t.Errorf("Expected: URL to be left untouched after invalid input, Got: %v", testVar.String())
+	}
+}